@@ -0,0 +1,3 @@
+module github.com/Asaad47/BioAlgos-Assignment1
+
+go 1.22
@@ -0,0 +1,383 @@
+// Package ahocorasick finds reads in genomes with an Aho-Corasick automaton
+// built from both strands of every read, exactly or with up to a given number
+// of mismatches. Automata can be saved and memory-mapped back, and genomes are
+// searched in parallel chunks.
+package ahocorasick
+
+import (
+	"strings"
+
+	"github.com/Asaad47/BioAlgos-Assignment1/src/iupac"
+)
+
+// alphabetSize is the number of symbols the automaton distinguishes:
+// a, c, g, t and one symbol shared by every other character
+const alphabetSize = 5
+
+// baseCodes maps a byte of a read or genome to its automaton symbol, ignoring case
+var baseCodes = func() [256]uint8 {
+	var codes [256]uint8
+	for i := range codes {
+		codes[i] = alphabetSize - 1
+	}
+	for i, base := range "acgt" {
+		codes[base] = uint8(i)
+		codes[base-'a'+'A'] = uint8(i)
+	}
+	return codes
+}()
+
+// AhoCorasick is a DNA-specialised automaton stored as flat arrays indexed by
+// state, with state 0 as the root. Once ComputeFailureLinks has run, next holds
+// a transition for every state and symbol, so Search never follows failure links.
+type AhoCorasick struct {
+	next     []int32 // next[state*alphabetSize+symbol] is the goto transition, -1 if absent while building
+	fail     []int32 // failure link of each state
+	dict     []int32 // dictionary link: nearest state on the failure chain where a pattern ends, 0 if none
+	depth    []int32 // length of the string spelled by the path to each state
+	terminal []int32 // number of the pattern ending at each state, -1 if none
+
+	// outputs of each pattern while building; ComputeFailureLinks packs them so that
+	// the outputs of pattern t are outputList[outputStart[t]:outputStart[t+1]]
+	outputs     [][]patternOutput
+	outputStart []int32
+	outputList  []patternOutput
+
+	readIDs   []string         // read IDs, indexed by the read number stored in outputs
+	readIndex map[string]int32 // maps read ID to read number while building
+
+	distinctPatterns int // number of states where at least one pattern ends
+
+	// approximate search: patterns are seeds of the reads, and every seed hit
+	// is verified against the genome allowing up to maxMismatches mismatches
+	maxMismatches int
+	reads         []readSequence // indexed by read number
+
+	mapped []byte // file backing the arrays of an automaton loaded by Load
+	source Source // read file the automaton was built from
+
+	// handling of ambiguity codes in searched genomes; with expansion, at most
+	// maxExpansions automaton states are followed at once before the scan restarts
+	ambiguity     iupac.Policy
+	maxExpansions int
+
+	walkFailLinks bool // report matches by walking the whole failure chain, used by the benchmarks only
+}
+
+// patternOutput identifies one read ending at a trie node; identical read
+// sequences share the node and each get their own output. Its layout is fixed
+// so saved automata can be memory-mapped.
+type patternOutput struct {
+	read   int32 // read number, see AhoCorasick.readIDs
+	offset int32 // offset of the pattern within the read, non-zero only for seeds
+	strand Strand
+	_      [3]byte
+}
+
+// readSequence holds both strands of a read for verifying approximate hits
+type readSequence struct {
+	forward string
+	reverse string
+}
+
+// candidate is a possible approximate occurrence of a read, located by one of its seeds
+type candidate struct {
+	read   int32
+	strand Strand
+	start  int
+}
+
+// Strand records which strand(s) of a genome a read was found on
+type Strand uint8
+
+const (
+	Forward Strand = 1 << iota // the read itself occurs in the genome
+	Reverse                    // the reverse complement of the read occurs in the genome
+)
+
+func (s Strand) String() string {
+	switch s {
+	case Forward:
+		return "+"
+	case Reverse:
+		return "-"
+	case Forward | Reverse:
+		return "+/-"
+	}
+	return "."
+}
+
+// StrandCounts holds the number of matches of a single read on each strand
+type StrandCounts struct {
+	forward int
+	reverse int
+}
+
+func (c *StrandCounts) Total() int {
+	return c.forward + c.reverse
+}
+
+// Strands returns the set of strands the read matched on
+func (c *StrandCounts) Strands() Strand {
+	var s Strand
+	if c.forward > 0 {
+		s |= Forward
+	}
+	if c.reverse > 0 {
+		s |= Reverse
+	}
+	return s
+}
+
+// Hit is a single occurrence of a read in a genome
+type Hit struct {
+	ReadID string
+	Record string // name of the FASTA record the read was found in
+	Start  int    // 0-based offset of the first matched base within the record
+	End    int    // 0-based offset one past the last matched base
+	Strand Strand // strand of the record the read lies on
+
+	Mismatches int // number of mismatching bases, always 0 for exact search
+}
+
+// RecordInfo describes one FASTA record of a searched genome file
+type RecordInfo struct {
+	Name      string
+	Length    int
+	Matches   int // number of hits within this record
+	Ambiguous int // number of ambiguity codes in the record
+	Skipped   int // number of positions where the search restarted because of ambiguity codes
+}
+
+// SearchResult holds every hit found while scanning one genome file
+type SearchResult struct {
+	Organism string
+	Records  []*RecordInfo
+	Hits     []Hit
+}
+
+// CountsByRead summarises the hits as the number of matches of each read per strand
+func (r *SearchResult) CountsByRead() map[string]*StrandCounts {
+	counts := make(map[string]*StrandCounts)
+	for _, hit := range r.Hits {
+		c, exists := counts[hit.ReadID]
+		if !exists {
+			c = &StrandCounts{}
+			counts[hit.ReadID] = c
+		}
+		if hit.Strand == Forward {
+			c.forward++
+		} else {
+			c.reverse++
+		}
+	}
+	return counts
+}
+
+// New returns an empty automaton. With maxMismatches greater than zero, reads
+// added by AddRead are split into seeds for approximate search.
+func New(maxMismatches int) *AhoCorasick {
+	ac := &AhoCorasick{
+		readIndex:     make(map[string]int32),
+		maxMismatches: maxMismatches,
+		maxExpansions: iupac.DefaultMaxExpansions,
+	}
+	ac.newState(0)
+	return ac
+}
+
+// SetAmbiguity sets how ambiguity codes in searched genomes are handled; with
+// expansion, at most maxExpansions states are followed at once
+func (ac *AhoCorasick) SetAmbiguity(policy iupac.Policy, maxExpansions int) {
+	ac.ambiguity = policy
+	ac.maxExpansions = maxExpansions
+}
+
+// MaxMismatches returns the number of mismatches allowed per read
+func (ac *AhoCorasick) MaxMismatches() int {
+	return ac.maxMismatches
+}
+
+// NumStates returns the number of states of the automaton
+func (ac *AhoCorasick) NumStates() int {
+	return len(ac.depth)
+}
+
+// DistinctPatterns returns the number of distinct pattern sequences
+func (ac *AhoCorasick) DistinctPatterns() int {
+	return ac.distinctPatterns
+}
+
+// ReadIDs returns the IDs of the reads in the automaton, in the order they were added
+func (ac *AhoCorasick) ReadIDs() []string {
+	return ac.readIDs
+}
+
+// newState appends a state without transitions and returns its index
+func (ac *AhoCorasick) newState(depth int32) int32 {
+	state := int32(len(ac.depth))
+	for i := 0; i < alphabetSize; i++ {
+		ac.next = append(ac.next, -1)
+	}
+	ac.fail = append(ac.fail, 0)
+	ac.dict = append(ac.dict, 0)
+	ac.depth = append(ac.depth, depth)
+	ac.terminal = append(ac.terminal, -1)
+	return state
+}
+
+func (ac *AhoCorasick) AddPattern(pattern string, readID string, strand Strand) {
+	ac.addOutput(pattern, patternOutput{read: ac.readNumber(readID), strand: strand})
+}
+
+// AddRead adds both strands of a read to the trie, or their seeds if the
+// automaton allows mismatches, and returns the number of patterns added
+func (ac *AhoCorasick) AddRead(readID string, seq string) int {
+	// insert both strands so reads sequenced from the minus strand can match
+	seq = strings.ToLower(seq)
+	if ac.maxMismatches > 0 {
+		return ac.addSeeds(seq, readID)
+	}
+	ac.AddPattern(seq, readID, Forward)
+	ac.AddPattern(reverseComplement(seq), readID, Reverse)
+	return 2
+}
+
+// readNumber returns the number identifying readID in pattern outputs,
+// assigning the next free number to a new read
+func (ac *AhoCorasick) readNumber(readID string) int32 {
+	if read, exists := ac.readIndex[readID]; exists {
+		return read
+	}
+	read := int32(len(ac.readIDs))
+	ac.readIndex[readID] = read
+	ac.readIDs = append(ac.readIDs, readID)
+	if ac.maxMismatches > 0 {
+		ac.reads = append(ac.reads, readSequence{})
+	}
+	return read
+}
+
+// addOutput inserts the pattern into the trie and attaches output to its last state
+func (ac *AhoCorasick) addOutput(pattern string, output patternOutput) {
+	current := int32(0)
+	for i := 0; i < len(pattern); i++ {
+		edge := int(current)*alphabetSize + int(baseCodes[pattern[i]])
+		if ac.next[edge] < 0 {
+			child := ac.newState(ac.depth[current] + 1)
+			ac.next[edge] = child
+		}
+		current = ac.next[edge]
+	}
+	if ac.terminal[current] < 0 {
+		ac.terminal[current] = int32(len(ac.outputs))
+		ac.outputs = append(ac.outputs, nil)
+		ac.distinctPatterns++
+	}
+	// a palindromic read reaches the same state from both strands
+	outputs := ac.outputs[ac.terminal[current]]
+	for i := range outputs {
+		if outputs[i].read == output.read && outputs[i].offset == output.offset {
+			outputs[i].strand |= output.strand
+			return
+		}
+	}
+	ac.outputs[ac.terminal[current]] = append(outputs, output)
+}
+
+// addSeeds splits both strands of a read into maxMismatches+1 non-overlapping seeds;
+// by the pigeonhole principle any occurrence with at most maxMismatches
+// mismatches contains at least one seed exactly
+func (ac *AhoCorasick) addSeeds(seq string, readID string) int {
+	numSeeds := ac.maxMismatches + 1
+	if len(seq) < numSeeds {
+		numSeeds = 1 // too short to split, fall back to exact matching of the whole read
+	}
+	seedLength := len(seq) / numSeeds
+
+	reads := map[Strand]string{Forward: seq, Reverse: reverseComplement(seq)}
+	read := ac.readNumber(readID)
+	ac.reads[read] = readSequence{forward: reads[Forward], reverse: reads[Reverse]}
+	for _, strand := range []Strand{Forward, Reverse} {
+		for i := 0; i < numSeeds; i++ {
+			start := i * seedLength
+			end := start + seedLength
+			if i == numSeeds-1 {
+				end = len(seq) // the last seed takes the remainder
+			}
+			ac.addOutput(reads[strand][start:end], patternOutput{read: read, strand: strand, offset: int32(start)})
+		}
+	}
+	return 2 * numSeeds
+}
+
+// ComputeFailureLinks sets the failure and dictionary links of every state in
+// breadth-first order and fills in each missing transition with the transition
+// of the failure state, turning the trie into a complete automaton. No pattern
+// can be added afterwards.
+func (ac *AhoCorasick) ComputeFailureLinks() {
+	queue := make([]int32, 0, len(ac.depth))
+	for symbol := 0; symbol < alphabetSize; symbol++ {
+		child := ac.next[symbol]
+		if child < 0 {
+			ac.next[symbol] = 0
+			continue
+		}
+		ac.fail[child] = 0
+		queue = append(queue, child)
+	}
+
+	for head := 0; head < len(queue); head++ {
+		current := queue[head]
+		failLink := ac.fail[current]
+		for symbol := 0; symbol < alphabetSize; symbol++ {
+			edge := int(current)*alphabetSize + symbol
+			child := ac.next[edge]
+			if child < 0 {
+				ac.next[edge] = ac.next[int(failLink)*alphabetSize+symbol]
+				continue
+			}
+			ac.fail[child] = ac.next[int(failLink)*alphabetSize+symbol]
+			if ac.terminal[ac.fail[child]] >= 0 {
+				ac.dict[child] = ac.fail[child]
+			} else {
+				ac.dict[child] = ac.dict[ac.fail[child]]
+			}
+			queue = append(queue, child)
+		}
+	}
+
+	// pack the outputs into one array
+	ac.outputStart = make([]int32, 0, len(ac.outputs)+1)
+	for _, outputs := range ac.outputs {
+		ac.outputStart = append(ac.outputStart, int32(len(ac.outputList)))
+		ac.outputList = append(ac.outputList, outputs...)
+	}
+	ac.outputStart = append(ac.outputStart, int32(len(ac.outputList)))
+	ac.outputs = nil
+	ac.readIndex = nil
+}
+
+// reverseComplement returns the reverse complement of a lowercase DNA sequence,
+// leaving any character other than a, c, g and t unchanged
+func reverseComplement(seq string) string {
+	rc := make([]byte, len(seq))
+	for i := 0; i < len(seq); i++ {
+		rc[len(seq)-1-i] = complementBase(seq[i])
+	}
+	return string(rc)
+}
+
+func complementBase(base byte) byte {
+	switch base {
+	case 'a':
+		return 't'
+	case 'c':
+		return 'g'
+	case 'g':
+		return 'c'
+	case 't':
+		return 'a'
+	}
+	return base
+}
@@ -0,0 +1,228 @@
+package ahocorasick
+
+import (
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Asaad47/BioAlgos-Assignment1/src/internal/testutil"
+	"github.com/Asaad47/BioAlgos-Assignment1/src/seqio"
+)
+
+// legacyNode and legacyAhoCorasick are the original map-based automaton,
+// kept only as the baseline of the benchmarks
+type legacyNode struct {
+	children map[rune]*legacyNode
+	failLink *legacyNode
+	pattern  bool
+}
+
+type legacyAhoCorasick struct {
+	root *legacyNode
+}
+
+func newLegacyAhoCorasick() *legacyAhoCorasick {
+	return &legacyAhoCorasick{root: &legacyNode{children: make(map[rune]*legacyNode)}}
+}
+
+func (ac *legacyAhoCorasick) addPattern(pattern string) {
+	current := ac.root
+	for _, char := range pattern {
+		if current.children[char] == nil {
+			current.children[char] = &legacyNode{children: make(map[rune]*legacyNode)}
+		}
+		current = current.children[char]
+	}
+	current.pattern = true
+}
+
+func (ac *legacyAhoCorasick) computeFailureLinks() {
+	queue := []*legacyNode{ac.root}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for char, child := range current.children {
+			queue = append(queue, child)
+			if current == ac.root {
+				child.failLink = ac.root
+				continue
+			}
+			failLink := current.failLink
+			for failLink != nil && failLink.children[char] == nil {
+				failLink = failLink.failLink
+			}
+			if failLink == nil {
+				child.failLink = ac.root
+			} else {
+				child.failLink = failLink.children[char]
+			}
+		}
+	}
+}
+
+// countMatches scans a FASTA file and returns the number of pattern occurrences
+func (ac *legacyAhoCorasick) countMatches(textFilePath string) (int, error) {
+	textFile, err := seqio.Open(textFilePath)
+	if err != nil {
+		return 0, err
+	}
+	defer textFile.Close()
+
+	matches := 0
+	reader := seqio.NewFastaReader(textFile)
+	for reader.Next() {
+		current := ac.root
+		sequence, err := io.ReadAll(reader)
+		if err != nil {
+			return 0, err
+		}
+		for _, char := range strings.ToLower(string(sequence)) {
+			for current != nil && current.children[char] == nil {
+				current = current.failLink
+			}
+			if current == nil {
+				current = ac.root
+				continue
+			}
+			current = current.children[char]
+			for temp := current; temp != nil; temp = temp.failLink {
+				if temp.pattern {
+					matches++
+				}
+			}
+		}
+	}
+	return matches, reader.Err()
+}
+
+// benchmarkData returns 150 bp reads sampled from both strands of the
+// genomes and the paths of the genomes: a random 1 Mbp genome, or the five
+// reference genomes with -reference-genomes
+func benchmarkData(b *testing.B) ([]string, []string) {
+	random := rand.New(rand.NewPCG(1, 2))
+	var genomes, genomeFiles []string
+	if testutil.UseReferenceGenomes() {
+		for _, reference := range testutil.ReferenceGenomes(b) {
+			genome, err := readGenome(reference.Path)
+			if err != nil {
+				b.Fatal(err)
+			}
+			for _, sequence := range genome.sequences {
+				genomes = append(genomes, strings.ToLower(string(sequence)))
+			}
+			genomeFiles = append(genomeFiles, reference.Path)
+		}
+	} else {
+		genomes = []string{testutil.RandomSequence(random, 1<<20)}
+		genomeFiles = []string{testutil.WriteFasta(b, "genome.fna", genomes[0])}
+	}
+
+	reads := make([]string, 5000)
+	for i := range reads {
+		genome := genomes[random.IntN(len(genomes))]
+		for len(genome) < 150 {
+			genome = genomes[random.IntN(len(genomes))]
+		}
+		start := random.IntN(len(genome) - 150)
+		reads[i] = genome[start : start+150]
+		if i%2 == 1 {
+			reads[i] = reverseComplement(reads[i])
+		}
+	}
+	return reads, genomeFiles
+}
+
+func buildLegacy(reads []string) *legacyAhoCorasick {
+	ac := newLegacyAhoCorasick()
+	for _, read := range reads {
+		ac.addPattern(read)
+		ac.addPattern(reverseComplement(read))
+	}
+	ac.computeFailureLinks()
+	return ac
+}
+
+func buildArray(reads []string) *AhoCorasick {
+	ac := New(0)
+	for i, read := range reads {
+		ac.AddRead(fmt.Sprint(i), read)
+	}
+	ac.ComputeFailureLinks()
+	return ac
+}
+
+// BenchmarkBuild compares the build time and memory of the array-based
+// automaton with the original map[rune]*Node trie
+func BenchmarkBuild(b *testing.B) {
+	reads, _ := benchmarkData(b)
+	b.Run("map", func(b *testing.B) {
+		memory, _ := testutil.HeapGrowth(func() *legacyAhoCorasick { return buildLegacy(reads) })
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			buildLegacy(reads)
+		}
+		b.ReportMetric(float64(memory)/(1<<20), "MB")
+	})
+	b.Run("array", func(b *testing.B) {
+		memory, _ := testutil.HeapGrowth(func() *AhoCorasick { return buildArray(reads) })
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			buildArray(reads)
+		}
+		b.ReportMetric(float64(memory)/(1<<20), "MB")
+	})
+}
+
+// BenchmarkSearch compares the search throughput of the original trie with
+// the array-based automaton, reporting matches through dictionary (output)
+// links or by walking the whole failure chain
+func BenchmarkSearch(b *testing.B) {
+	reads, genomeFiles := benchmarkData(b)
+	var size int64
+	for _, genomeFile := range genomeFiles {
+		genome, err := os.Stat(genomeFile)
+		if err != nil {
+			b.Fatal(err)
+		}
+		size += genome.Size()
+	}
+	b.Run("map", func(b *testing.B) {
+		legacy := buildLegacy(reads)
+		b.SetBytes(size)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			for _, genomeFile := range genomeFiles {
+				if _, err := legacy.countMatches(genomeFile); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	array := buildArray(reads)
+	for _, walkFailLinks := range []bool{false, true} {
+		name := "array-output-links"
+		if walkFailLinks {
+			name = "array-failure-chain"
+		}
+		b.Run(name, func(b *testing.B) {
+			array.walkFailLinks = walkFailLinks
+			defer func() { array.walkFailLinks = false }()
+			b.SetBytes(size)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for _, genomeFile := range genomeFiles {
+					if _, err := array.Search(genomeFile, ""); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
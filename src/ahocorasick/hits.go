@@ -0,0 +1,68 @@
+package ahocorasick
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WriteBED writes the hits as BED6 lines with 0-based, half-open intervals
+func WriteBED(w io.Writer, results []*SearchResult) error {
+	bw := bufio.NewWriter(w)
+	for _, result := range results {
+		for _, hit := range result.Hits {
+			fmt.Fprintf(bw, "%s\t%d\t%d\t%s\t0\t%s\n",
+				hit.Record, hit.Start, hit.End, hit.ReadID, hit.Strand)
+		}
+	}
+	return bw.Flush()
+}
+
+// WritePAF writes the hits as PAF lines, with the read as query and the
+// genome record as target
+func WritePAF(w io.Writer, results []*SearchResult) error {
+	bw := bufio.NewWriter(w)
+	for _, result := range results {
+		recordLengths := make(map[string]int)
+		for _, record := range result.Records {
+			recordLengths[record.Name] = record.Length
+		}
+		for _, hit := range result.Hits {
+			length := hit.End - hit.Start
+			fmt.Fprintf(bw, "%s\t%d\t0\t%d\t%s\t%s\t%d\t%d\t%d\t%d\t%d\t255\tNM:i:%d\n",
+				hit.ReadID, length, length, hit.Strand, hit.Record,
+				recordLengths[hit.Record], hit.Start, hit.End, length-hit.Mismatches, length, hit.Mismatches)
+		}
+	}
+	return bw.Flush()
+}
+
+// ExportHits writes the hits of one read file to <dir>/<read file name>.bed and .paf
+func ExportHits(dir string, readFile string, results []*SearchResult) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	base := filepath.Join(dir, strings.TrimSuffix(filepath.Base(readFile), filepath.Ext(readFile)))
+
+	writers := map[string]func(io.Writer, []*SearchResult) error{
+		".bed": WriteBED,
+		".paf": WritePAF,
+	}
+	for ext, write := range writers {
+		file, err := os.Create(base + ext)
+		if err != nil {
+			return err
+		}
+		if err := write(file, results); err != nil {
+			file.Close()
+			return err
+		}
+		if err := file.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+package ahocorasick
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"unsafe"
+
+	"github.com/Asaad47/BioAlgos-Assignment1/src/internal/binfile"
+	"github.com/Asaad47/BioAlgos-Assignment1/src/iupac"
+)
+
+const (
+	automatonMagic      = "BIOACAUT"
+	automatonVersion    = 1
+	automatonHeaderSize = 80
+)
+
+// automatonHeader is the fixed-size header of a saved automaton. It is followed
+// by the little-endian arrays next, dict, depth, terminal, outputStart and
+// outputList, then by the path of the read file and the read IDs (and read
+// sequences for approximate search), each prefixed by its uint32 length.
+type automatonHeader struct {
+	Magic            [8]byte
+	Version          uint32
+	AlphabetSize     uint32
+	NumStates        uint32
+	NumPatterns      uint32
+	NumOutputs       uint32
+	NumReads         uint32
+	MaxMismatches    uint32
+	DistinctPatterns uint32
+	StringsSize      uint64
+	SourceSize       uint64 // size of the read file
+	SourceModTime    int64  // modification time of the read file
+	Checksum         uint32 // CRC-32C of everything after the header
+	_                [12]byte
+}
+
+// Source identifies the read file an automaton was built from, so that a saved
+// automaton is only reused while the file is unchanged
+type Source struct {
+	Path    string // absolute path of the read file
+	Size    int64
+	ModTime int64 // modification time in nanoseconds since the Unix epoch
+}
+
+// StatSource returns the identity of a read file
+func StatSource(path string) (Source, error) {
+	absolute, err := filepath.Abs(path)
+	if err != nil {
+		return Source{}, err
+	}
+	info, err := os.Stat(absolute)
+	if err != nil {
+		return Source{}, err
+	}
+	return Source{Path: absolute, Size: info.Size(), ModTime: info.ModTime().UnixNano()}, nil
+}
+
+// SetSource records the read file the automaton was built from, which Save
+// stores with it
+func (ac *AhoCorasick) SetSource(source Source) {
+	ac.source = source
+}
+
+// Source returns the read file the automaton was built from, as set by
+// SetSource or read back by Load
+func (ac *AhoCorasick) Source() Source {
+	return ac.source
+}
+
+// Save writes the automaton to path so that Load can reuse it
+// without rebuilding; ComputeFailureLinks must have been called first. The
+// file is replaced only once it is completely written, so processes that
+// mapped the previous file keep reading it.
+func (ac *AhoCorasick) Save(path string) error {
+	if ac.outputStart == nil {
+		return fmt.Errorf("automaton has no failure links yet")
+	}
+
+	var stringTable bytes.Buffer
+	writeString := func(str string) {
+		binary.Write(&stringTable, binary.LittleEndian, uint32(len(str)))
+		stringTable.WriteString(str)
+	}
+	writeString(ac.source.Path)
+	for read, readID := range ac.readIDs {
+		writeString(readID)
+		if ac.maxMismatches > 0 {
+			writeString(ac.reads[read].forward)
+		}
+	}
+
+	header := automatonHeader{
+		Version:          automatonVersion,
+		AlphabetSize:     alphabetSize,
+		NumStates:        uint32(len(ac.depth)),
+		NumPatterns:      uint32(len(ac.outputStart) - 1),
+		NumOutputs:       uint32(len(ac.outputList)),
+		NumReads:         uint32(len(ac.readIDs)),
+		MaxMismatches:    uint32(ac.maxMismatches),
+		DistinctPatterns: uint32(ac.distinctPatterns),
+		StringsSize:      uint64(stringTable.Len()),
+		SourceSize:       uint64(ac.source.Size),
+		SourceModTime:    ac.source.ModTime,
+	}
+	copy(header.Magic[:], automatonMagic)
+
+	file, err := binfile.Create(path, automatonHeaderSize)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	for _, section := range []any{ac.next, ac.dict, ac.depth, ac.terminal, ac.outputStart, ac.outputList} {
+		if err := binary.Write(file, binary.LittleEndian, section); err != nil {
+			return err
+		}
+	}
+	if _, err := file.Write(stringTable.Bytes()); err != nil {
+		return err
+	}
+	if header.Checksum, err = file.Checksum(); err != nil {
+		return err
+	}
+	return file.Commit(&header)
+}
+
+// Load memory-maps an automaton written by Save. The transition
+// arrays are used in place, so the automaton is read-only and should be
+// released with Close.
+func Load(path string) (*AhoCorasick, error) {
+	data, err := binfile.Map(path)
+	if err != nil {
+		return nil, err
+	}
+	ac, err := decodeAutomaton(data)
+	if err != nil {
+		binfile.Unmap(data)
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return ac, nil
+}
+
+// decodeAutomaton validates the header and checksum of a saved automaton and
+// builds an AhoCorasick whose arrays point into data
+func decodeAutomaton(data []byte) (*AhoCorasick, error) {
+	if len(data) < automatonHeaderSize {
+		return nil, fmt.Errorf("file too short for an automaton")
+	}
+	var header automatonHeader
+	if err := binary.Read(bytes.NewReader(data[:automatonHeaderSize]), binary.LittleEndian, &header); err != nil {
+		return nil, err
+	}
+	if string(header.Magic[:]) != automatonMagic {
+		return nil, fmt.Errorf("not an automaton file")
+	}
+	if header.Version != automatonVersion {
+		return nil, fmt.Errorf("unsupported automaton version %d (expected %d)", header.Version, automatonVersion)
+	}
+	if header.AlphabetSize != alphabetSize {
+		return nil, fmt.Errorf("alphabet size %d does not match %d", header.AlphabetSize, alphabetSize)
+	}
+
+	numStates := int(header.NumStates)
+	outputSize := int(unsafe.Sizeof(patternOutput{}))
+	expectedSize := automatonHeaderSize + 4*numStates*(alphabetSize+3) +
+		4*(int(header.NumPatterns)+1) + outputSize*int(header.NumOutputs) + int(header.StringsSize)
+	if len(data) != expectedSize {
+		return nil, fmt.Errorf("file size %d does not match header (%d)", len(data), expectedSize)
+	}
+	if crc32.Checksum(data[automatonHeaderSize:], binfile.Castagnoli) != header.Checksum {
+		return nil, fmt.Errorf("checksum mismatch, file is corrupted")
+	}
+
+	ac := &AhoCorasick{
+		maxMismatches:    int(header.MaxMismatches),
+		distinctPatterns: int(header.DistinctPatterns),
+		maxExpansions:    iupac.DefaultMaxExpansions,
+		mapped:           data,
+	}
+	offset := automatonHeaderSize
+	ac.next = binfile.MappedSlice[int32](data, &offset, numStates*alphabetSize)
+	ac.dict = binfile.MappedSlice[int32](data, &offset, numStates)
+	ac.depth = binfile.MappedSlice[int32](data, &offset, numStates)
+	ac.terminal = binfile.MappedSlice[int32](data, &offset, numStates)
+	ac.outputStart = binfile.MappedSlice[int32](data, &offset, int(header.NumPatterns)+1)
+	ac.outputList = binfile.MappedSlice[patternOutput](data, &offset, int(header.NumOutputs))
+
+	readString := func() (string, error) {
+		if offset+4 > len(data) {
+			return "", fmt.Errorf("truncated string table")
+		}
+		length := int(binary.LittleEndian.Uint32(data[offset:]))
+		offset += 4
+		if offset+length > len(data) {
+			return "", fmt.Errorf("truncated string table")
+		}
+		str := string(data[offset : offset+length])
+		offset += length
+		return str, nil
+	}
+	ac.source = Source{Size: int64(header.SourceSize), ModTime: header.SourceModTime}
+	var err error
+	if ac.source.Path, err = readString(); err != nil {
+		return nil, err
+	}
+	ac.readIDs = make([]string, header.NumReads)
+	if ac.maxMismatches > 0 {
+		ac.reads = make([]readSequence, header.NumReads)
+	}
+	for read := range ac.readIDs {
+		if ac.readIDs[read], err = readString(); err != nil {
+			return nil, err
+		}
+		if ac.maxMismatches > 0 {
+			forward, err := readString()
+			if err != nil {
+				return nil, err
+			}
+			ac.reads[read] = readSequence{forward: forward, reverse: reverseComplement(forward)}
+		}
+	}
+	return ac, nil
+}
+
+// Close releases the memory mapping of an automaton loaded by Load
+func (ac *AhoCorasick) Close() error {
+	if ac.mapped == nil {
+		return nil
+	}
+	err := binfile.Unmap(ac.mapped)
+	ac.mapped = nil
+	return err
+}
@@ -0,0 +1,112 @@
+package ahocorasick
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/Asaad47/BioAlgos-Assignment1/src/internal/testutil"
+)
+
+// TestSaveLoad saves an automaton and checks that the loaded automaton finds
+// the same hits, for exact and k-mismatch search
+func TestSaveLoad(t *testing.T) {
+	random := rand.New(rand.NewPCG(1, 2))
+	records := []string{testutil.RandomSequence(random, 20000), testutil.RandomSequence(random, 7000)}
+	genome := testutil.WriteFasta(t, "genome.fna", records...)
+	for _, maxMismatches := range []int{0, 2} {
+		t.Run(fmt.Sprintf("mismatches=%d", maxMismatches), func(t *testing.T) {
+			built := New(maxMismatches)
+			for readID, read := range testReads(random, records, maxMismatches) {
+				built.AddRead(readID, read)
+			}
+			built.ComputeFailureLinks()
+			source := Source{Path: "/data/reads.fastq", Size: 1234, ModTime: 5678}
+			built.SetSource(source)
+
+			path := filepath.Join(t.TempDir(), "reads.aca")
+			if err := built.Save(path); err != nil {
+				t.Fatal(err)
+			}
+			loaded, err := Load(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer loaded.Close()
+
+			if loaded.MaxMismatches() != maxMismatches || loaded.Source() != source || loaded.NumStates() != built.NumStates() ||
+				loaded.DistinctPatterns() != built.DistinctPatterns() || !slices.Equal(loaded.ReadIDs(), built.ReadIDs()) {
+				t.Fatalf("loaded %d mismatches, source %+v, %d states, %d patterns and %d reads, want %d, %+v, %d, %d and %d",
+					loaded.MaxMismatches(), loaded.Source(), loaded.NumStates(), loaded.DistinctPatterns(), len(loaded.ReadIDs()),
+					maxMismatches, source, built.NumStates(), built.DistinctPatterns(), len(built.ReadIDs()))
+			}
+			want, err := built.SearchGenomes([]string{genome}, []string{"genome"}, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := loaded.SearchGenomes([]string{genome}, []string{"genome"}, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(want[0].Hits) == 0 || !reflect.DeepEqual(got, want) {
+				t.Errorf("loaded automaton found %d hits, built one %d", len(got[0].Hits), len(want[0].Hits))
+			}
+		})
+	}
+}
+
+func TestSaveWithoutFailureLinks(t *testing.T) {
+	ac := New(0)
+	ac.AddRead("read", "acgtacgt")
+	if err := ac.Save(filepath.Join(t.TempDir(), "reads.aca")); err == nil {
+		t.Error("saved an automaton without failure links")
+	}
+}
+
+// TestLoadDamaged checks that truncated or corrupted automaton files are
+// rejected instead of being mapped
+func TestLoadDamaged(t *testing.T) {
+	random := rand.New(rand.NewPCG(1, 2))
+	records := []string{testutil.RandomSequence(random, 5000)}
+	ac := New(1)
+	for readID, read := range testReads(random, records, 1) {
+		ac.AddRead(readID, read)
+	}
+	ac.ComputeFailureLinks()
+	path := filepath.Join(t.TempDir(), "reads.aca")
+	if err := ac.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	damage := map[string]func([]byte) []byte{
+		"empty":          func(data []byte) []byte { return nil },
+		"header only":    func(data []byte) []byte { return data[:automatonHeaderSize] },
+		"truncated":      func(data []byte) []byte { return data[:len(data)-1] },
+		"extended":       func(data []byte) []byte { return append(data, 0) },
+		"magic":          func(data []byte) []byte { data[0] = 'X'; return data },
+		"version":        func(data []byte) []byte { data[8]++; return data },
+		"state count":    func(data []byte) []byte { data[16]++; return data },
+		"transition":     func(data []byte) []byte { data[automatonHeaderSize] ^= 1; return data },
+		"string table":   func(data []byte) []byte { data[len(data)-1] ^= 1; return data },
+		"checksum field": func(data []byte) []byte { data[64] ^= 1; return data },
+	}
+	for name, damage := range damage {
+		t.Run(name, func(t *testing.T) {
+			if err := os.WriteFile(path, damage(slices.Clone(data)), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if loaded, err := Load(path); err == nil {
+				loaded.Close()
+				t.Errorf("loaded a damaged automaton")
+			}
+		})
+	}
+}
@@ -0,0 +1,477 @@
+package ahocorasick
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/Asaad47/BioAlgos-Assignment1/src/iupac"
+	"github.com/Asaad47/BioAlgos-Assignment1/src/seqio"
+)
+
+// Search streams the records of a FASTA file through the automaton and returns
+// the hits found in them
+func (ac *AhoCorasick) Search(textFilePath string, organismName string) (*SearchResult, error) {
+	textFile, err := seqio.Open(textFilePath)
+	if err != nil {
+		return nil, err
+	}
+	defer textFile.Close()
+
+	reader := seqio.NewFastaReader(textFile)
+	result := &SearchResult{Organism: organismName}
+	current := []int32{0}
+	buffer := make([]byte, 1<<16)
+
+	// in approximate mode the record is kept in memory to verify seed hits once it is complete
+	var sequence []byte
+
+	for reader.Next() {
+		// a FASTA record, named by the first word of its header; the automaton
+		// is reset so no match can span two records
+		name := reader.Name()
+		if name == "" {
+			// sequence without a header line, e.g. a plain text file
+			name = filepath.Base(textFilePath)
+		}
+		record := &RecordInfo{Name: name}
+		result.Records = append(result.Records, record)
+		records := newRecordScanner(ac, record)
+		current = append(current[:0], 0)
+		sequence = sequence[:0]
+
+		for {
+			n, err := reader.Read(buffer)
+			chunk := buffer[:n]
+			if ac.maxMismatches > 0 {
+				sequence = append(sequence, chunk...)
+			}
+
+			chunkStart := record.Length
+			var skipped int
+			current, skipped = ac.advance(current, chunk, 0, func(state int32, i int) {
+				records.report(state, chunkStart+i)
+			})
+			record.Length += n
+			record.Ambiguous += iupac.CountAmbiguous(chunk)
+			record.Skipped += skipped
+			if err != nil {
+				break
+			}
+		}
+
+		hits := records.finish(sequence)
+		record.Matches = len(hits)
+		result.Hits = append(result.Hits, hits...)
+	}
+
+	if err := reader.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", textFilePath, err)
+	}
+	return result, nil
+}
+
+// searchChunkSize is the number of record positions each SearchGenomes task
+// reports, a variable so tests can split small records
+var searchChunkSize = 1 << 20
+
+// genomeRecords holds the records of one genome file read into memory
+type genomeRecords struct {
+	records   []*RecordInfo
+	sequences [][]byte
+}
+
+// searchChunk is one unit of work of SearchGenomes: the occurrences whose last
+// character lies in [from, to) of a record
+type searchChunk struct {
+	genome  int
+	record  int
+	from    int
+	to      int
+	hits    []Hit
+	skipped int
+}
+
+// SearchGenomes searches every genome file concurrently on up to workers
+// goroutines, splitting records into chunks that overlap by the longest pattern
+// length minus one so that no occurrence is lost at a chunk boundary. Results
+// are returned in the order of genomeFiles, with hits sorted by record,
+// position, read ID and strand, independently of the number of workers. It
+// fails if any genome file cannot be read.
+func (ac *AhoCorasick) SearchGenomes(genomeFiles []string, organismNames []string, workers int) ([]*SearchResult, error) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	genomes := make([]*genomeRecords, len(genomeFiles))
+	errs := make([]error, len(genomeFiles))
+	parallelFor(len(genomeFiles), workers, func(i int) {
+		genomes[i], errs[i] = readGenome(genomeFiles[i])
+	})
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	var chunks []*searchChunk
+	for g, genome := range genomes {
+		for r, sequence := range genome.sequences {
+			for from := 0; from < len(sequence); from += searchChunkSize {
+				to := min(from+searchChunkSize, len(sequence))
+				chunks = append(chunks, &searchChunk{genome: g, record: r, from: from, to: to})
+			}
+		}
+	}
+
+	overlap := ac.maxPatternLength() - 1
+	parallelFor(len(chunks), workers, func(i int) {
+		chunk := chunks[i]
+		genome := genomes[chunk.genome]
+		sequence := genome.sequences[chunk.record]
+		records := newRecordScanner(ac, genome.records[chunk.record])
+
+		// start early enough to see every pattern ending in the chunk, but only report those
+		begin := max(0, chunk.from-overlap)
+		_, chunk.skipped = ac.advance([]int32{0}, sequence[begin:chunk.to], chunk.from-begin, func(state int32, i int) {
+			records.report(state, begin+i)
+		})
+		chunk.hits = records.finish(sequence)
+	})
+
+	// merge the chunks of each genome in a fixed order
+	results := make([]*SearchResult, len(genomeFiles))
+	for g, genome := range genomes {
+		results[g] = &SearchResult{Organism: organismNames[g], Records: genome.records}
+	}
+	for _, chunk := range chunks {
+		results[chunk.genome].Hits = append(results[chunk.genome].Hits, chunk.hits...)
+		results[chunk.genome].Records[chunk.record].Skipped += chunk.skipped
+	}
+	for g, result := range results {
+		result.Hits = sortHits(result.Hits, genomes[g].records)
+		recordIndex := make(map[string]*RecordInfo)
+		for _, record := range result.Records {
+			recordIndex[record.Name] = record
+		}
+		for _, hit := range result.Hits {
+			recordIndex[hit.Record].Matches++
+		}
+	}
+	return results, nil
+}
+
+// sortHits orders hits by record (in file order), position, read ID and strand
+// and drops duplicates, which approximate search can find from two chunks
+func sortHits(hits []Hit, records []*RecordInfo) []Hit {
+	recordOrder := make(map[string]int)
+	for i, record := range records {
+		recordOrder[record.Name] = i
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		a, b := hits[i], hits[j]
+		if a.Record != b.Record {
+			return recordOrder[a.Record] < recordOrder[b.Record]
+		}
+		if a.Start != b.Start {
+			return a.Start < b.Start
+		}
+		if a.End != b.End {
+			return a.End < b.End
+		}
+		if a.ReadID != b.ReadID {
+			return a.ReadID < b.ReadID
+		}
+		return a.Strand < b.Strand
+	})
+
+	unique := hits[:0]
+	for i, hit := range hits {
+		if i == 0 || hit != hits[i-1] {
+			unique = append(unique, hit)
+		}
+	}
+	return unique
+}
+
+// parallelFor calls fn for every index in [0, n) on up to workers goroutines
+func parallelFor(n int, workers int, fn func(i int)) {
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(workers, n); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+}
+
+// readGenome reads every record of a FASTA file into memory
+func readGenome(path string) (*genomeRecords, error) {
+	file, err := seqio.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	genome := &genomeRecords{}
+	reader := seqio.NewFastaReader(file)
+	for {
+		record, err := reader.ReadRecord()
+		if err == io.EOF {
+			return genome, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		name := record.Name
+		if name == "" {
+			// sequence without a header line, e.g. a plain text file
+			name = filepath.Base(path)
+		}
+		genome.records = append(genome.records, &RecordInfo{
+			Name:      name,
+			Length:    len(record.Sequence),
+			Ambiguous: iupac.CountAmbiguous(record.Sequence),
+		})
+		genome.sequences = append(genome.sequences, record.Sequence)
+	}
+}
+
+// maxPatternLength returns the length of the longest pattern in the automaton
+func (ac *AhoCorasick) maxPatternLength() int {
+	longest := 0
+	for state, pattern := range ac.terminal {
+		if pattern >= 0 && int(ac.depth[state]) > longest {
+			longest = int(ac.depth[state])
+		}
+	}
+	return longest
+}
+
+// advance feeds text through the automaton from the active states and returns
+// the new active states. For every pattern occurrence whose last character is
+// at an index i >= reportFrom of text, it calls report with the state where the
+// pattern ends and i. It also returns the number of such positions where the
+// scan restarted from the root because of an ambiguity code.
+//
+// Normally a single state is active; an ambiguity code expanded under the
+// ambiguity policy activates one state per base, until they merge again
+// at most the longest pattern length later.
+func (ac *AhoCorasick) advance(states []int32, text []byte, reportFrom int, report func(state int32, i int)) ([]int32, int) {
+	skipped := 0
+	var expanded, outputs []int32
+	restart := func(i int) {
+		states = append(states[:0], 0)
+		if i >= reportFrom {
+			skipped++
+		}
+	}
+
+	for i := 0; i < len(text); i++ {
+		bases := iupac.Bases[text[i]]
+		ambiguous := bits.OnesCount8(bases) != 1
+		if len(states) == 1 && !ambiguous {
+			state := ac.next[int(states[0])*alphabetSize+int(baseCodes[text[i]])]
+			states[0] = state
+			if i >= reportFrom {
+				for output := state; output > 0; output = ac.nextOutput(output) {
+					if ac.terminal[output] >= 0 {
+						report(output, i)
+					}
+				}
+			}
+			continue
+		}
+
+		if ambiguous {
+			switch ac.ambiguity {
+			case iupac.Break:
+				restart(i)
+				continue
+			case iupac.Wildcard:
+				bases = 1 | 2 | 4 | 8
+			}
+		}
+		// follow every active state on every base the character stands for
+		expanded = expanded[:0]
+		for _, state := range states {
+			for symbol := 0; symbol < 4; symbol++ {
+				if bases&(1<<symbol) == 0 {
+					continue
+				}
+				next := ac.next[int(state)*alphabetSize+symbol]
+				if !slices.Contains(expanded, next) {
+					expanded = append(expanded, next)
+				}
+			}
+		}
+		if len(expanded) > ac.maxExpansions {
+			restart(i)
+			continue
+		}
+		states = append(states[:0], expanded...)
+		if i < reportFrom {
+			continue
+		}
+
+		// report each pattern once, even if several active states end with it
+		outputs = outputs[:0]
+		for _, state := range states {
+			for output := state; output > 0; output = ac.nextOutput(output) {
+				if ac.terminal[output] >= 0 && !slices.Contains(outputs, output) {
+					outputs = append(outputs, output)
+				}
+			}
+		}
+		for _, output := range outputs {
+			report(output, i)
+		}
+	}
+	return states, skipped
+}
+
+// nextOutput returns the next state after state on its failure chain where a
+// pattern ends, or 0 if there is none
+func (ac *AhoCorasick) nextOutput(state int32) int32 {
+	if !ac.walkFailLinks {
+		return ac.dict[state]
+	}
+	for state = ac.fail[state]; state > 0 && ac.terminal[state] < 0; state = ac.fail[state] {
+	}
+	return state
+}
+
+// recordScanner turns the pattern occurrences found in one record into hits or,
+// in approximate mode, into candidates that are verified once the record is read
+type recordScanner struct {
+	ac         *AhoCorasick
+	record     *RecordInfo
+	hits       []Hit
+	candidates []candidate
+	seen       map[candidate]bool
+}
+
+func newRecordScanner(ac *AhoCorasick, record *RecordInfo) *recordScanner {
+	return &recordScanner{ac: ac, record: record, seen: make(map[candidate]bool)}
+}
+
+// report records the occurrence of the patterns ending at state, whose last
+// character is at offset last of the record
+func (rs *recordScanner) report(state int32, last int) {
+	ac := rs.ac
+	pattern := ac.terminal[state]
+	// record one hit (or candidate, for seeds) for each read and strand the pattern came from
+	start := last - int(ac.depth[state]) + 1
+	for _, output := range ac.outputList[ac.outputStart[pattern]:ac.outputStart[pattern+1]] {
+		for _, strand := range []Strand{Forward, Reverse} {
+			if output.strand&strand == 0 {
+				continue
+			}
+			if ac.maxMismatches > 0 {
+				c := candidate{read: output.read, strand: strand, start: start - int(output.offset)}
+				if c.start >= 0 && !rs.seen[c] {
+					rs.seen[c] = true
+					rs.candidates = append(rs.candidates, c)
+				}
+				continue
+			}
+			rs.hits = append(rs.hits, Hit{
+				ReadID: ac.readIDs[output.read],
+				Record: rs.record.Name,
+				Start:  start,
+				End:    last + 1,
+				Strand: strand,
+			})
+		}
+	}
+}
+
+// finish returns the hits of the record, verifying candidates against its
+// complete sequence in approximate mode
+func (rs *recordScanner) finish(sequence []byte) []Hit {
+	if rs.ac.maxMismatches > 0 {
+		rs.hits = append(rs.hits, rs.ac.verifyCandidates(rs.record, sequence, rs.candidates)...)
+	}
+	return rs.hits
+}
+
+// verifyCandidates compares each candidate occurrence with the record sequence
+// and returns those with at most maxMismatches mismatches as hits
+func (ac *AhoCorasick) verifyCandidates(record *RecordInfo, sequence []byte, candidates []candidate) []Hit {
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].start != candidates[j].start {
+			return candidates[i].start < candidates[j].start
+		}
+		if candidates[i].read != candidates[j].read {
+			return ac.readIDs[candidates[i].read] < ac.readIDs[candidates[j].read]
+		}
+		return candidates[i].strand < candidates[j].strand
+	})
+
+	var hits []Hit
+	for _, c := range candidates {
+		read := ac.reads[c.read].forward
+		if c.strand == Reverse {
+			read = ac.reads[c.read].reverse
+		}
+		end := c.start + len(read)
+		if end > len(sequence) {
+			continue
+		}
+		mismatches := ac.hammingDistance(read, sequence[c.start:end])
+		if mismatches > ac.maxMismatches {
+			continue
+		}
+		hits = append(hits, Hit{
+			ReadID:     ac.readIDs[c.read],
+			Record:     record.Name,
+			Start:      c.start,
+			End:        end,
+			Strand:     c.strand,
+			Mismatches: mismatches,
+		})
+	}
+	return hits
+}
+
+// hammingDistance counts the mismatching positions of a read and an equally
+// long text, stopping early once the count exceeds maxMismatches
+func (ac *AhoCorasick) hammingDistance(read string, text []byte) int {
+	mismatches := 0
+	for i := 0; i < len(read); i++ {
+		if !ac.baseMatches(read[i], text[i]) {
+			mismatches++
+			if mismatches > ac.maxMismatches {
+				break
+			}
+		}
+	}
+	return mismatches
+}
+
+// baseMatches reports whether a read base matches a genome base, which may be
+// an ambiguity code handled according to the ambiguity policy
+func (ac *AhoCorasick) baseMatches(readBase byte, textBase byte) bool {
+	if !iupac.IsAmbiguous(textBase) {
+		return baseCodes[readBase] == baseCodes[textBase]
+	}
+	switch ac.ambiguity {
+	case iupac.Break:
+		return false
+	case iupac.Wildcard:
+		return true
+	}
+	return iupac.Bases[readBase]&iupac.Bases[textBase] != 0
+}
@@ -0,0 +1,136 @@
+package ahocorasick
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/Asaad47/BioAlgos-Assignment1/src/internal/testutil"
+)
+
+// testReads samples reads from both strands of the records and mutates up to
+// maxMismatches+1 of their bases, so some fall just outside the allowed
+// mismatches. A few reads are random, a few are repeated under another ID and
+// one is a palindrome.
+func testReads(random *rand.Rand, records []string, maxMismatches int) map[string]string {
+	reads := make(map[string]string)
+	for i := 0; i < 300; i++ {
+		length := 30 + random.IntN(40)
+		record := records[random.IntN(len(records))]
+		start := random.IntN(len(record) - length)
+		read := []byte(record[start : start+length])
+		for range random.IntN(maxMismatches + 2) {
+			read[random.IntN(length)] = "acgt"[random.IntN(4)]
+		}
+		reads[fmt.Sprintf("read%d", i)] = string(read)
+		if i%2 == 1 {
+			reads[fmt.Sprintf("read%d", i)] = reverseComplement(string(read))
+		}
+	}
+	for i := 0; i < 20; i++ {
+		reads[fmt.Sprintf("random%d", i)] = testutil.RandomSequence(random, 40)
+	}
+	for i := 0; i < 20; i++ {
+		reads[fmt.Sprintf("copy%d", i)] = reads[fmt.Sprintf("read%d", i)]
+	}
+	reads["palindrome"] = records[0][100:120] + reverseComplement(records[0][100:120])
+	return reads
+}
+
+// naiveSearch compares both strands of every read with every position of the
+// records and returns the hits with at most maxMismatches mismatches
+func naiveSearch(reads map[string]string, records []string, maxMismatches int) []Hit {
+	var hits []Hit
+	for readID, read := range reads {
+		for _, strand := range []Strand{Forward, Reverse} {
+			pattern := read
+			if strand == Reverse {
+				pattern = reverseComplement(read)
+			}
+			for r, record := range records {
+				for start := 0; start+len(pattern) <= len(record); start++ {
+					mismatches := 0
+					for i := 0; i < len(pattern) && mismatches <= maxMismatches; i++ {
+						if pattern[i] != record[start+i] {
+							mismatches++
+						}
+					}
+					if mismatches <= maxMismatches {
+						hits = append(hits, Hit{
+							ReadID:     readID,
+							Record:     fmt.Sprintf("record%d", r+1),
+							Start:      start,
+							End:        start + len(pattern),
+							Strand:     strand,
+							Mismatches: mismatches,
+						})
+					}
+				}
+			}
+		}
+	}
+	return hits
+}
+
+// TestSearch checks the hits of exact and k-mismatch search against a naive
+// scan, for Search and for SearchGenomes with records split into several chunks
+func TestSearch(t *testing.T) {
+	random := rand.New(rand.NewPCG(1, 2))
+	records := []string{testutil.RandomSequence(random, 20000), testutil.RandomSequence(random, 7000)}
+	genome := testutil.WriteFasta(t, "genome.fna", records...)
+	recordInfos := []*RecordInfo{{Name: "record1"}, {Name: "record2"}}
+
+	defer func(size int) { searchChunkSize = size }(searchChunkSize)
+	searchChunkSize = 3000
+
+	for _, maxMismatches := range []int{0, 1, 2, 3} {
+		t.Run(fmt.Sprintf("mismatches=%d", maxMismatches), func(t *testing.T) {
+			reads := testReads(random, records, maxMismatches)
+			ac := New(maxMismatches)
+			for readID, read := range reads {
+				ac.AddRead(readID, read)
+			}
+			ac.ComputeFailureLinks()
+			want := sortHits(naiveSearch(reads, records, maxMismatches), recordInfos)
+
+			result, err := ac.Search(genome, "genome")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := sortHits(result.Hits, result.Records); !slices.Equal(got, want) {
+				t.Errorf("Search found %d hits, want %d", len(got), len(want))
+			}
+
+			for _, workers := range []int{1, 4} {
+				results, err := ac.SearchGenomes([]string{genome, genome}, []string{"genome", "copy"}, workers)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, genomeResult := range results {
+					if !slices.Equal(genomeResult.Hits, want) {
+						t.Errorf("SearchGenomes with %d workers found %d hits in %s, want %d", workers, len(genomeResult.Hits), genomeResult.Organism, len(want))
+					}
+					for i, record := range genomeResult.Records {
+						if *record != *result.Records[i] {
+							t.Errorf("SearchGenomes with %d workers described %s as %+v, Search as %+v", workers, record.Name, *record, *result.Records[i])
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSearchGenomesMissingFile(t *testing.T) {
+	ac := New(0)
+	ac.AddRead("read", "acgtacgt")
+	ac.ComputeFailureLinks()
+	genome := testutil.WriteFasta(t, "genome.fna", "acgtacgtacgt")
+	if _, err := ac.SearchGenomes([]string{genome, genome + ".missing"}, []string{"genome", "missing"}, 2); err == nil {
+		t.Error("searched a missing genome file without an error")
+	}
+	if _, err := ac.Search(genome+".missing", "missing"); err == nil {
+		t.Error("searched a missing genome file without an error")
+	}
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/Asaad47/BioAlgos-Assignment1/src/ahocorasick"
+	"github.com/Asaad47/BioAlgos-Assignment1/src/refs"
+	"github.com/Asaad47/BioAlgos-Assignment1/src/seqio"
+)
+
+// ReadMatch holds information about which organisms a read matches
+type ReadMatch struct {
+	readID         string
+	matchedOrgs    map[string]bool
+	matchedCount   map[string]int
+	matchedStrands map[string]ahocorasick.Strand
+}
+
+// buildAutomaton adds every read of a FASTQ file to a new automaton. With
+// maxMismatches greater than zero the reads are split into seeds for
+// approximate search. It fails at the first malformed record unless
+// skipMalformed is set, and returns the number of records skipped.
+func buildAutomaton(readFile string, maxMismatches int, skipMalformed bool) (*ahocorasick.AhoCorasick, []string, int, error) {
+	file, err := seqio.Open(readFile)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	defer file.Close()
+
+	ac := ahocorasick.New(maxMismatches)
+	numPatterns := 0
+	readIDs := []string{}
+	reader := seqio.NewFastqReader(file)
+	reader.SkipMalformed = skipMalformed
+	for {
+		record, err := reader.ReadRecord()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf("%s: %w", readFile, err)
+		}
+		readID := record.Name
+		readIDs = append(readIDs, readID)
+		numPatterns += ac.AddRead(readID, string(record.Sequence))
+	}
+	if reader.Skipped() > 0 {
+		fmt.Printf("Skipped %d malformed reads (first at %v)\n", reader.Skipped(), reader.FirstSkipped())
+	}
+
+	ac.ComputeFailureLinks()
+	fmt.Printf("Added %d patterns (%d distinct sequences) from %d reads\n",
+		numPatterns, ac.DistinctPatterns(), len(readIDs))
+	return ac, readIDs, reader.Skipped(), nil
+}
+
+// loadOrBuildAutomaton reuses the automaton saved for readFile in dir if it was
+// built from the same, unchanged file with the same number of mismatches, and
+// otherwise builds and saves it. With an empty dir the automaton is always
+// built and never saved, as it is when malformed records were skipped, so
+// that a later run reports them again. It fails if the read file cannot be read.
+func loadOrBuildAutomaton(dir string, readFile string, maxMismatches int, skipMalformed bool) (*ahocorasick.AhoCorasick, []string, error) {
+	if dir == "" {
+		ac, readIDs, _, err := buildAutomaton(readFile, maxMismatches, skipMalformed)
+		return ac, readIDs, err
+	}
+	source, err := ahocorasick.StatSource(readFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// read files with the same name in different directories get their own automaton
+	name := strings.TrimSuffix(filepath.Base(readFile), filepath.Ext(readFile))
+	path := filepath.Join(dir, fmt.Sprintf("%s-%08x.aca", name, crc32.ChecksumIEEE([]byte(source.Path))))
+	ac, err := ahocorasick.Load(path)
+	if err == nil && ac.MaxMismatches() == maxMismatches && ac.Source() == source {
+		fmt.Printf("Loaded automaton with %d states (%d distinct sequences) for %d reads from %s\n",
+			ac.NumStates(), ac.DistinctPatterns(), len(ac.ReadIDs()), path)
+		return ac, ac.ReadIDs(), nil
+	}
+	if err == nil {
+		if ac.Source() != source {
+			fmt.Printf("Automaton in %s was built from another version of %s, rebuilding\n", path, readFile)
+		}
+		ac.Close()
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		fmt.Println("Error loading automaton, rebuilding:", err)
+	}
+
+	ac, readIDs, skipped, err := buildAutomaton(readFile, maxMismatches, skipMalformed)
+	if err != nil {
+		return nil, nil, err
+	}
+	ac.SetSource(source)
+	if skipped > 0 {
+		fmt.Printf("Not saving the automaton of %s, which has malformed reads\n", readFile)
+	} else if err := os.MkdirAll(dir, 0o755); err != nil {
+		fmt.Println("Error saving automaton:", err)
+	} else if err := ac.Save(path); err != nil {
+		fmt.Println("Error saving automaton:", err)
+	} else {
+		fmt.Printf("Saved automaton to %s\n", path)
+	}
+	return ac, readIDs, nil
+}
+
+func runACClassify(args []string) {
+	flags := newFlagSet("ac-classify")
+	loadReferences := referenceFlags(flags)
+	readFiles := readsFlag(flags)
+	ambiguity, maxExpansions := ambiguityFlags(flags)
+	hitsDir := flags.String("hits-out", "", "directory to write every hit to as BED and PAF files (disabled if empty)")
+	maxMismatches := flags.Int("mismatches", 0, "maximum number of mismatches (Hamming distance) allowed per read")
+	automatonDir := flags.String("automaton-dir", "", "directory to save built automata to and reload them from (disabled if empty)")
+	workers := flags.Int("workers", runtime.NumCPU(), "number of goroutines searching the genomes")
+	skipMalformed := flags.Bool("skip-malformed", false, "skip malformed FASTQ records instead of stopping at the first one")
+	flags.Parse(args)
+
+	policy, err := ambiguity()
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(2)
+	}
+	references, err := loadReferences()
+	if err != nil {
+		fmt.Println("Error loading references:", err)
+		os.Exit(1)
+	}
+	genomeFiles := refs.Paths(references)
+	orgNames := refs.Names(references)
+
+	for _, readFile := range readFiles() {
+		fmt.Printf("\n=== Processing %s ===\n", readFile)
+
+		readMatches := make(map[string]*ReadMatch)
+
+		ac, readIDs, err := loadOrBuildAutomaton(*automatonDir, readFile, *maxMismatches, *skipMalformed)
+		if err != nil {
+			fmt.Println("Error reading pattern file:", err)
+			os.Exit(1)
+		}
+		ac.SetAmbiguity(policy, *maxExpansions)
+
+		for _, readID := range readIDs {
+			readMatches[readID] = &ReadMatch{
+				readID:         readID,
+				matchedOrgs:    make(map[string]bool),
+				matchedCount:   make(map[string]int),
+				matchedStrands: make(map[string]ahocorasick.Strand),
+			}
+		}
+
+		fmt.Printf("Searching against %s genomes with %d workers...\n", strings.Join(orgNames, ", "), *workers)
+
+		results, err := ac.SearchGenomes(genomeFiles, orgNames, *workers)
+		if err != nil {
+			fmt.Println("Error searching genomes:", err)
+			os.Exit(1)
+		}
+		mismatchCounts := make(map[int]int) // maps number of mismatches to count of hits
+		for _, result := range results {
+			orgName := result.Organism
+			for _, hit := range result.Hits {
+				mismatchCounts[hit.Mismatches]++
+			}
+
+			for readID, matchInfo := range result.CountsByRead() {
+				if _, exists := readMatches[readID]; exists {
+					readMatches[readID].matchedOrgs[orgName] = true
+					readMatches[readID].matchedCount[orgName] = matchInfo.Total()
+					readMatches[readID].matchedStrands[orgName] = matchInfo.Strands()
+				}
+			}
+		}
+		ac.Close()
+
+		if *hitsDir != "" {
+			if err := ahocorasick.ExportHits(*hitsDir, readFile, results); err != nil {
+				fmt.Println("Error writing hits:", err)
+			} else {
+				fmt.Printf("Wrote hits to %s\n", *hitsDir)
+			}
+		}
+
+		fmt.Printf("\n--- Classification Report for %s ---\n", readFile)
+
+		orgReadCounts := make(map[string]int) // maps organism name to count of reads
+		multipleMatches := 0
+		uniqueMatches := 0
+		noMatches := 0
+		strandCounts := make(map[ahocorasick.Strand]int) // maps matched strand(s) to count of reads
+
+		for _, match := range readMatches {
+			if len(match.matchedOrgs) > 1 {
+				multipleMatches++
+			} else if len(match.matchedOrgs) == 1 {
+				uniqueMatches++
+			} else {
+				noMatches++
+			}
+
+			var strands ahocorasick.Strand
+			for org := range match.matchedOrgs {
+				orgReadCounts[org]++
+				strands |= match.matchedStrands[org]
+			}
+			if strands != 0 {
+				strandCounts[strands]++
+			}
+		}
+
+		// print organism-specific counts
+		fmt.Println("\nReads matching each organism:")
+		for _, orgName := range orgNames {
+			fmt.Printf("%s: %d reads\n", orgName, orgReadCounts[orgName])
+		}
+
+		// print record-specific counts, useful for genomes with plasmids
+		fmt.Println("\nMatches per genome record:")
+		for _, result := range results {
+			for _, record := range result.Records {
+				fmt.Printf("%s %s (%d bp): %d matches\n",
+					result.Organism, record.Name, record.Length, record.Matches)
+			}
+		}
+
+		// print ambiguity code counts
+		fmt.Printf("\nAmbiguity codes in genomes (policy: %s):\n", policy)
+		for _, result := range results {
+			ambiguous, skipped := 0, 0
+			for _, record := range result.Records {
+				ambiguous += record.Ambiguous
+				skipped += record.Skipped
+			}
+			fmt.Printf("%s: %d ambiguous bases, %d positions skipped\n", result.Organism, ambiguous, skipped)
+		}
+
+		// print summary
+		fmt.Printf("\nTotal reads: %d\n", len(readMatches))
+		fmt.Printf("Reads matching exactly one organism: %d (%.2f%%)\n",
+			uniqueMatches, float64(uniqueMatches)*100/float64(len(readMatches)))
+		fmt.Printf("Reads matching multiple organisms: %d (%.2f%%)\n",
+			multipleMatches, float64(multipleMatches)*100/float64(len(readMatches)))
+		fmt.Printf("Reads with no matches: %d (%.2f%%)\n",
+			noMatches, float64(noMatches)*100/float64(len(readMatches)))
+
+		// print mismatch summary for approximate search
+		if *maxMismatches > 0 {
+			fmt.Println("\nHits by number of mismatches:")
+			for mismatches := 0; mismatches <= *maxMismatches; mismatches++ {
+				fmt.Printf("%d mismatches: %d hits\n", mismatches, mismatchCounts[mismatches])
+			}
+		}
+
+		// print strand summary
+		fmt.Println("\nMatched reads by strand:")
+		fmt.Printf("Forward strand only (+): %d reads\n", strandCounts[ahocorasick.Forward])
+		fmt.Printf("Reverse strand only (-): %d reads\n", strandCounts[ahocorasick.Reverse])
+		fmt.Printf("Both strands (+/-): %d reads\n", strandCounts[ahocorasick.Forward|ahocorasick.Reverse])
+	}
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Asaad47/BioAlgos-Assignment1/src/iupac"
+	"github.com/Asaad47/BioAlgos-Assignment1/src/kmer"
+	"github.com/Asaad47/BioAlgos-Assignment1/src/refs"
+	"github.com/Asaad47/BioAlgos-Assignment1/src/taxonomy"
+)
+
+// printTaxonTree prints, depth first from the given taxon, every taxon with
+// reads in its clade: the reads in the clade, the reads assigned to the taxon
+// itself, its rank and its name
+func printTaxonTree(tree *taxonomy.Taxonomy, id int, depth int, cladeReads, taxonReads map[int]int) {
+	if cladeReads[id] == 0 {
+		return
+	}
+	fmt.Printf("	%8d %8d  %-13s %s%s\n", cladeReads[id], taxonReads[id], tree.Rank(id), strings.Repeat("  ", depth), tree.Name(id))
+	for _, child := range tree.Children(id) {
+		printTaxonTree(tree, child, depth+1, cladeReads, taxonReads)
+	}
+}
+
+func runClassify(args []string) {
+	flags := newFlagSet("classify")
+	loadReferences := referenceFlags(flags)
+	readFiles := readsFlag(flags)
+	ambiguity, maxExpansions := ambiguityFlags(flags)
+	kmerLength := flags.Int("k", 31, fmt.Sprintf("k-mer length (1-%d)", kmer.MaxK))
+	canonical := flags.Bool("canonical", false, "index and query canonical k-mers, so reads match on either strand")
+	lca := flags.Bool("lca", false, "label each k-mer with the LCA of the genomes containing it and assign reads to taxa as Kraken does")
+	taxdumpDir := flags.String("taxdump", "", "directory with the nodes.dmp and names.dmp files of an NCBI taxdump, for -lca (built-in subset if empty)")
+	assemblySummary := flags.String("assembly-summary", "", "NCBI assembly_summary.txt mapping the accessions of references without a taxid to taxa, for -lca (built-in subset if empty)")
+	paired := flags.Bool("paired", false, "pair the R1 and R2 read files (consecutive in -reads) and classify each fragment on the k-mers of both mates")
+	skipMalformed := flags.Bool("skip-malformed", false, "skip malformed FASTQ records instead of stopping at the first one")
+	indexFile := flags.String("index", "", "k-mer index written by kmer-build -o to load instead of building one (its k and k-mer kind are used)")
+	flags.Parse(args)
+
+	policy, err := ambiguity()
+	if err != nil {
+		log.Fatalf("Invalid -ambiguity: %v", err)
+	}
+	k := *kmerLength
+	checkK(k)
+	references, err := loadReferences()
+	if err != nil {
+		log.Fatalf("Failed to load references: %v", err)
+	}
+	var readFilePairs [][2]string
+	if *paired {
+		files := readFiles()
+		if len(files)%2 != 0 {
+			log.Fatalf("Invalid -reads: -paired needs an R2 file after each R1 file, got %d files", len(files))
+		}
+		for i := 0; i < len(files); i += 2 {
+			readFilePairs = append(readFilePairs, [2]string{files[i], files[i+1]})
+		}
+	}
+
+	var kmerIndex *kmer.Index
+	var loadTime time.Duration
+	if *indexFile != "" {
+		start := time.Now()
+		if kmerIndex, err = kmer.Load(*indexFile); err != nil {
+			log.Fatalf("Failed to load k-mer index: %v", err)
+		}
+		defer kmerIndex.Close()
+		flags.Visit(func(f *flag.Flag) {
+			if f.Name == "k" && k != kmerIndex.K() || f.Name == "canonical" && *canonical != kmerIndex.Canonical() {
+				log.Fatalf("Invalid -%s: does not match the k-mer index %s", f.Name, *indexFile)
+			}
+		})
+		if !slices.Equal(kmerIndex.Genomes(), refs.Names(references)) {
+			log.Fatalf("K-mer index %s was built from genomes %s, not %s", *indexFile,
+				strings.Join(kmerIndex.Genomes(), ", "), strings.Join(refs.Names(references), ", "))
+		}
+		k, *canonical = kmerIndex.K(), kmerIndex.Canonical()
+		loadTime = time.Since(start)
+	}
+
+	fmt.Println("\n" + strings.Repeat("=", 80))
+	fmt.Println("K-mer Based Classification Report")
+	fmt.Println(strings.Repeat("=", 80))
+
+	kmerKind := "forward"
+	if *canonical {
+		kmerKind = "canonical"
+	}
+	if kmerIndex != nil {
+		fmt.Printf("\nLoaded k-mer index with k = %d (%s k-mers) of %d unique k-mers from %s in %v\n",
+			k, kmerKind, kmerIndex.Len(), *indexFile, loadTime.Round(time.Millisecond))
+	} else {
+		fmt.Printf("\nBuilding k-mer index with k = %d (%s k-mers, ambiguity codes: %s):\n", k, kmerKind, policy)
+		if kmerIndex, _, err = kmer.BuildIndex(references, k, *canonical, policy, *maxExpansions, os.Stdout); err != nil {
+			log.Fatalf("Failed to build k-mer index: %v", err)
+		}
+	}
+
+	var tree *taxonomy.Taxonomy
+	var kmerTaxa []int32
+	if *lca {
+		tree = taxonomy.Builtin()
+		if *taxdumpDir != "" {
+			if tree, err = taxonomy.LoadDir(*taxdumpDir); err != nil {
+				log.Fatalf("Failed to load taxonomy: %v", err)
+			}
+		}
+		assemblies := taxonomy.BuiltinAssemblies()
+		if *assemblySummary != "" {
+			if assemblies, err = taxonomy.LoadAssemblySummary(*assemblySummary); err != nil {
+				log.Fatalf("Failed to load assembly summary: %v", err)
+			}
+		}
+		genomeTaxa, err := kmer.GenomeTaxa(references, tree, assemblies)
+		if err != nil {
+			log.Fatalf("Failed to find the taxa of the references: %v", err)
+		}
+		if kmerTaxa, err = kmer.LabelKmers(kmerIndex, tree, genomeTaxa); err != nil {
+			log.Fatalf("Failed to label k-mers: %v", err)
+		}
+	}
+
+	readFilter := &iupac.Filter{Policy: policy, MaxExpansions: *maxExpansions}
+	var readMatches map[string]*kmer.ReadMatch
+	var pairStats kmer.PairStats
+	unit, unitTitle := "reads", "Reads"
+	if *paired {
+		fmt.Printf("\nClassifying read pairs.\n")
+		readMatches, pairStats, err = kmer.ClassifyPairs(readFilePairs, kmerIndex, readFilter, kmerTaxa, *skipMalformed, os.Stdout)
+		unit, unitTitle = "fragments", "Fragments"
+	} else {
+		fmt.Printf("\nClassifying reads.\n")
+		readMatches, err = kmer.ClassifyReads(readFiles(), kmerIndex, readFilter, kmerTaxa, *skipMalformed, os.Stdout)
+	}
+	if err != nil {
+		log.Fatalf("Failed to classify reads: %v", err)
+	}
+
+	orgReadCounts := make(map[string]int)
+	orgKmerCounts := make(map[string]int)
+	multipleMatches := 0
+	uniqueMatches := 0
+	noMatches := 0
+
+	for _, match := range readMatches {
+		if len(match.MatchedOrgs) > 1 {
+			multipleMatches++
+		} else if len(match.MatchedOrgs) == 1 {
+			uniqueMatches++
+		} else {
+			noMatches++
+		}
+		for org := range match.MatchedOrgs {
+			orgReadCounts[org]++
+			orgKmerCounts[org] += match.MatchedOrgs[org]
+		}
+	}
+
+	// Report:
+	fmt.Printf("\n1. Classification Results:\n")
+	fmt.Printf("	Total sequence %s processed: %d\n", unit, len(readMatches))
+	fmt.Printf("	Matched (k-mer) %s per organism:\n", unit)
+	for _, orgName := range refs.Names(references) {
+		fmt.Printf("     %-15s: %d %s, %d k-mer matches\n", orgName, orgReadCounts[orgName], unit, orgKmerCounts[orgName])
+	}
+
+	fmt.Printf("\n2. Match Statistics:\n")
+	fmt.Printf("	%s with unique matches: %d (%.2f%%)\n",
+		unitTitle, uniqueMatches, float64(uniqueMatches)*100/float64(len(readMatches)))
+	fmt.Printf("	%s with multiple matches: %d (%.2f%%)\n",
+		unitTitle, multipleMatches, float64(multipleMatches)*100/float64(len(readMatches)))
+	fmt.Printf("	%s with no matches: %d (%.2f%%)\n",
+		unitTitle, noMatches, float64(noMatches)*100/float64(len(readMatches)))
+	fmt.Printf("	Read k-mers with ambiguity codes: %d skipped, %d k-mers from expansions\n",
+		readFilter.Skipped, readFilter.Expanded)
+
+	if *paired {
+		pairs := pairStats.Pairs()
+		fmt.Printf("\n3. Read Pair Statistics:\n")
+		fmt.Printf("	Read pairs: %d, unpaired reads (mate not found): %d\n", pairs, pairStats.Orphans)
+		fmt.Printf("	Concordant pairs (mates match a common organism): %d (%.2f%%)\n",
+			pairStats.Concordant, float64(pairStats.Concordant)*100/float64(pairs))
+		fmt.Printf("	Discordant pairs (mates match different organisms): %d (%.2f%%)\n",
+			pairStats.Discordant, float64(pairStats.Discordant)*100/float64(pairs))
+		fmt.Printf("	Pairs with one matching mate: %d (%.2f%%)\n",
+			pairStats.SingleMate, float64(pairStats.SingleMate)*100/float64(pairs))
+		fmt.Printf("	Pairs with no matching mate: %d (%.2f%%)\n",
+			pairStats.Unmatched, float64(pairStats.Unmatched)*100/float64(pairs))
+	}
+
+	if *lca {
+		section := 3
+		if *paired {
+			section = 4
+		}
+		cladeReads := make(map[int]int)
+		taxonReads := make(map[int]int)
+		rankReads := make(map[string]int)
+		for _, match := range readMatches {
+			taxon := kmer.ClassifyTaxon(tree, match.TaxonHits)
+			if taxon == 0 {
+				continue
+			}
+			taxonReads[taxon]++
+			rankReads[tree.Rank(taxon)]++
+			for id := taxon; ; id = tree.Parent(id) {
+				cladeReads[id]++
+				if id == taxonomy.Root {
+					break
+				}
+			}
+		}
+		classified := cladeReads[taxonomy.Root]
+
+		fmt.Printf("\n%d. Taxonomic Classification (k-mers labelled with the LCA of their genomes):\n", section)
+		fmt.Printf("	Classified %s: %d (%.2f%%), unclassified: %d\n", unit, classified,
+			float64(classified)*100/float64(len(readMatches)), len(readMatches)-classified)
+		fmt.Printf("	%s per rank of their assigned taxon:\n", unitTitle)
+		for _, rank := range []string{"strain", "subspecies", "species", "species group", "genus", "family", "order", "class", "phylum", "clade", "domain", "no rank"} {
+			if rankReads[rank] > 0 {
+				fmt.Printf("     %-13s: %d\n", rank, rankReads[rank])
+			}
+		}
+		fmt.Printf("	%8s %8s  %-13s %s\n", "clade", "taxon", "rank", "name")
+		printTaxonTree(tree, taxonomy.Root, 0, cladeReads, taxonReads)
+	}
+
+	fmt.Println("\n" + strings.Repeat("=", 80))
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Asaad47/BioAlgos-Assignment1/src/kmer"
+	"github.com/Asaad47/BioAlgos-Assignment1/src/refs"
+)
+
+// checkK exits if k is not a valid k-mer length
+func checkK(k int) {
+	if k < 1 || k > kmer.MaxK {
+		log.Fatalf("Invalid -k: %d is not between 1 and %d", k, kmer.MaxK)
+	}
+}
+
+func runKmerBuild(args []string) {
+	flags := newFlagSet("kmer-build")
+	loadReferences := referenceFlags(flags)
+	ambiguity, maxExpansions := ambiguityFlags(flags)
+	k := flags.Int("k", 31, fmt.Sprintf("k-mer length (1-%d)", kmer.MaxK))
+	canonical := flags.Bool("canonical", false, "index canonical k-mers, so a k-mer and its reverse complement are counted once")
+	output := flags.String("o", "", "file to write the index to, for classify -index (not written if empty)")
+	flags.Parse(args)
+
+	policy, err := ambiguity()
+	if err != nil {
+		log.Fatalf("Invalid -ambiguity: %v", err)
+	}
+	checkK(*k)
+	references, err := loadReferences()
+	if err != nil {
+		log.Fatalf("Failed to load references: %v", err)
+	}
+
+	kmerKind := "forward"
+	if *canonical {
+		kmerKind = "canonical"
+	}
+	fmt.Printf("Building k-mer index with k = %d (%s k-mers, ambiguity codes: %s):\n", *k, kmerKind, policy)
+	start := time.Now()
+	kmerIndex, totalGenomeLength, err := kmer.BuildIndex(references, *k, *canonical, policy, *maxExpansions, os.Stdout)
+	if err != nil {
+		log.Fatalf("Failed to build k-mer index: %v", err)
+	}
+	fmt.Printf("Indexed %d unique k-mers of %d genomes (%d bp) in %v\n",
+		kmerIndex.Len(), len(references), totalGenomeLength, time.Since(start).Round(time.Millisecond))
+
+	if *output != "" {
+		if err := kmerIndex.Save(*output); err != nil {
+			log.Fatalf("Failed to write k-mer index: %v", err)
+		}
+		fmt.Printf("Wrote k-mer index to %s\n", *output)
+	}
+}
+
+func runKmerStats(args []string) {
+	flags := newFlagSet("kmer-stats")
+	loadReferences := referenceFlags(flags)
+	ambiguity, maxExpansions := ambiguityFlags(flags)
+	kmerLength := flags.Int("k", 31, fmt.Sprintf("k-mer length (1-%d)", kmer.MaxK))
+	canonical := flags.Bool("canonical", false, "also index canonical k-mers and report their counts")
+	flags.Parse(args)
+
+	policy, err := ambiguity()
+	if err != nil {
+		log.Fatalf("Invalid -ambiguity: %v", err)
+	}
+	k := *kmerLength
+	checkK(k)
+	references, err := loadReferences()
+	if err != nil {
+		log.Fatalf("Failed to load references: %v", err)
+	}
+
+	fmt.Println("\n" + strings.Repeat("=", 80))
+	fmt.Println("K-mer Index Analysis Report")
+	fmt.Println(strings.Repeat("=", 80))
+
+	fmt.Printf("\nBuilding k-mer index with k = %d (ambiguity codes: %s):\n", k, policy)
+	kmerIndex, totalGenomeLength, err := kmer.BuildIndex(references, k, false, policy, *maxExpansions, os.Stdout)
+	if err != nil {
+		log.Fatalf("Failed to build k-mer index: %v", err)
+	}
+
+	totalKmers := kmerIndex.Len()
+	maxTheoreticalKmers := new(big.Int).Lsh(big.NewInt(1), uint(2*k)) // 4^k possible k-mers for DNA
+	theoreticalKmers := totalGenomeLength - (k-1)*len(references)
+
+	kmersPerOrg := kmer.KmersPerGenome(kmerIndex)
+
+	// Report:
+	fmt.Printf("\n1. Data Structure Description:\n")
+	fmt.Printf("	Used a kmer.Index: k-mers packed at 2 bits per base into one uint64 key (k <= %d) or two (k <= %d),\n", kmer.MaxSmallK, kmer.MaxK)
+	fmt.Printf("	each mapped to a row of per-genome counts.\n")
+
+	fmt.Printf("\n2. K-mer Index Statistics:\n")
+	fmt.Printf("	Total unique k-mers in index: %d\n", totalKmers)
+	fmt.Printf("	K-mers per organism:\n")
+	for _, orgName := range refs.Names(references) {
+		fmt.Printf("	%-15s: %d unique k-mers\n", orgName, kmersPerOrg[orgName])
+	}
+	if *canonical {
+		// a k-mer and its reverse complement are counted once
+		canonicalIndex, _, err := kmer.BuildIndex(references, k, true, policy, *maxExpansions, io.Discard)
+		if err != nil {
+			log.Fatalf("Failed to build k-mer index: %v", err)
+		}
+		canonicalPerOrg := kmer.KmersPerGenome(canonicalIndex)
+		fmt.Printf("	Total unique canonical k-mers in index: %d\n", canonicalIndex.Len())
+		fmt.Printf("	Canonical k-mers per organism:\n")
+		for _, orgName := range refs.Names(references) {
+			fmt.Printf("	%-15s: %d unique canonical k-mers\n", orgName, canonicalPerOrg[orgName])
+		}
+	}
+
+	fmt.Printf("\n3. Theoretical and Discrepancy Analysis:\n")
+	fmt.Printf("	The actual number of k-mers: %d\n", totalKmers)
+	fmt.Printf("	The max theoretical number of k-mers (4^k): %d\n", maxTheoreticalKmers)
+	fmt.Printf("	The theoretical number of k-mers (total genome length - (k-1) * number of genomes): %d\n", theoreticalKmers)
+
+	fmt.Println("\n" + strings.Repeat("=", 80))
+}
@@ -0,0 +1,139 @@
+// Command bioalgos classifies metagenomic reads against reference genomes.
+// Each subcommand runs one of the assignment's methods:
+//
+//	ac-classify         find reads in the genomes with Aho-Corasick (Task 1.2)
+//	kmer-build          build the k-mer index of the genomes (Task 2.1)
+//	kmer-stats          report statistics of the k-mer index (Task 2.1)
+//	classify            classify reads by their k-mers (Task 2.2)
+//	minimizer-classify  classify reads by their minimizers (Task 2.3)
+//	add-genome          add genomes to a saved minimizer index (Task 2.3)
+//
+// Run it from the src/ directory, e.g. go run ./cmd/bioalgos classify -k 25.
+// Reference genomes are read from a manifest (-references) or listed directly
+// (-genomes), and reads from the FASTQ files given by -reads.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Asaad47/BioAlgos-Assignment1/src/iupac"
+	"github.com/Asaad47/BioAlgos-Assignment1/src/refs"
+)
+
+// defaultReads are the simulated read files, R1 and R2 of each sample in turn
+var defaultReads = []string{
+	"../data/sequence_reads/simulated_reads_no_errors_10k_R1.fastq",
+	"../data/sequence_reads/simulated_reads_no_errors_10k_R2.fastq",
+	"../data/sequence_reads/simulated_reads_miseq_10k_R1.fastq",
+	"../data/sequence_reads/simulated_reads_miseq_10k_R2.fastq",
+}
+
+var commands = []struct {
+	name    string
+	summary string
+	run     func(args []string)
+}{
+	{"ac-classify", "find reads in the genomes with Aho-Corasick (Task 1.2)", runACClassify},
+	{"kmer-build", "build the k-mer index of the genomes (Task 2.1)", runKmerBuild},
+	{"kmer-stats", "report statistics of the k-mer index (Task 2.1)", runKmerStats},
+	{"classify", "classify reads by their k-mers (Task 2.2)", runClassify},
+	{"minimizer-classify", "classify reads by their minimizers (Task 2.3)", runMinimizerClassify},
+	{"add-genome", "add genomes to a saved minimizer index (Task 2.3)", runAddGenome},
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: bioalgos <command> [flags]\n\nCommands:\n")
+	for _, command := range commands {
+		fmt.Fprintf(os.Stderr, "  %-20s %s\n", command.name, command.summary)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun bioalgos <command> -h for the flags of a command.\n")
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	name := os.Args[1]
+	if name == "-h" || name == "-help" || name == "--help" || name == "help" {
+		usage()
+		return
+	}
+	for _, command := range commands {
+		if command.name == name {
+			command.run(os.Args[2:])
+			return
+		}
+	}
+	fmt.Fprintf(os.Stderr, "bioalgos: unknown command %q\n\n", name)
+	usage()
+	os.Exit(2)
+}
+
+// newFlagSet returns the flag set of a subcommand
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: bioalgos %s [flags]\n\nFlags:\n", name)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// referenceFlags adds the flags selecting the reference genomes and returns a
+// function loading them once the flags are parsed
+func referenceFlags(fs *flag.FlagSet) func() ([]refs.Reference, error) {
+	manifest := fs.String("references", refs.DefaultManifest, "manifest (TSV or JSON) of the reference genomes")
+	genomes := fs.String("genomes", "", "comma-separated FASTA files of the reference genomes, named after their files, instead of -references")
+	return func() ([]refs.Reference, error) {
+		if *genomes == "" {
+			return refs.Load(*manifest)
+		}
+		var references []refs.Reference
+		for _, path := range splitList(*genomes) {
+			references = append(references, refs.Reference{Name: genomeName(path), Path: path})
+		}
+		return references, nil
+	}
+}
+
+// genomeName names a genome after its file, without directory and extensions
+func genomeName(path string) string {
+	name := filepath.Base(path)
+	name = strings.TrimSuffix(strings.TrimSuffix(name, ".gz"), ".bz2")
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// readsFlag adds the flag listing the read files and returns a function
+// splitting it once the flags are parsed
+func readsFlag(fs *flag.FlagSet) func() []string {
+	reads := fs.String("reads", strings.Join(defaultReads, ","), "comma-separated FASTQ files of the reads to classify")
+	return func() []string {
+		return splitList(*reads)
+	}
+}
+
+// ambiguityFlags adds the flags handling IUPAC ambiguity codes and returns a
+// function parsing the policy once the flags are parsed
+func ambiguityFlags(fs *flag.FlagSet) (func() (iupac.Policy, error), *int) {
+	name := fs.String("ambiguity", "break", "handling of IUPAC ambiguity codes: break, expand or wildcard")
+	maxExpansions := fs.Int("max-expansions", iupac.DefaultMaxExpansions, "maximum number of k-mers (or automaton states) an ambiguity code is expanded into")
+	return func() (iupac.Policy, error) {
+		return iupac.ParsePolicy(*name)
+	}, maxExpansions
+}
+
+// splitList splits a comma-separated list, dropping empty items
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Asaad47/BioAlgos-Assignment1/src/iupac"
+	"github.com/Asaad47/BioAlgos-Assignment1/src/minimizer"
+	"github.com/Asaad47/BioAlgos-Assignment1/src/refs"
+)
+
+// checkWindowSize exits if k or w are not valid minimizer parameters
+func checkWindowSize(k, w int) {
+	if k < 1 || k > minimizer.MaxK || w < 1 {
+		log.Fatalf("Invalid -k or -w: k must be between 1 and %d and w at least 1, got k=%d and w=%d", minimizer.MaxK, k, w)
+	}
+}
+
+// orderFlags adds the flags choosing the order of the k-mers of each window
+// and returns a function parsing it once the flags are parsed
+func orderFlags(fs *flag.FlagSet) func() (minimizer.Order, error) {
+	name := fs.String("order", "lexicographic", "ranking of the k-mers of each window: lexicographic, hash, random or frequency")
+	seed := fs.Uint64("seed", 0, "seed of the random order (picked at random if 0)")
+	fraction := fs.Float64("frequent-fraction", minimizer.DefaultFrequentFraction, "fraction of the most frequent k-mers of the genomes ranked last by the frequency order")
+	return func() (minimizer.Order, error) {
+		ordering, err := minimizer.ParseOrdering(*name)
+		if err != nil {
+			return minimizer.Order{}, err
+		}
+		if *fraction < 0 || *fraction > 1 {
+			return minimizer.Order{}, fmt.Errorf("frequent k-mer fraction %v is not between 0 and 1", *fraction)
+		}
+		order := minimizer.Order{Ordering: ordering, Seed: *seed, Fraction: *fraction}
+		if ordering == minimizer.Random && order.Seed == 0 {
+			order.Seed = rand.Uint64()
+		}
+		return order, nil
+	}
+}
+
+// checkIndexFlags exits if -k, -w, -order or -seed was set to a value other
+// than the one the minimizer index was built with
+func checkIndexFlags(flags *flag.FlagSet, index *minimizer.Index, indexFile string) {
+	order := index.Order()
+	flags.Visit(func(f *flag.Flag) {
+		value := f.Value.String()
+		if f.Name == "k" && value != strconv.Itoa(index.K()) || f.Name == "w" && value != strconv.Itoa(index.W()) ||
+			f.Name == "order" && value != order.Ordering.String() ||
+			f.Name == "seed" && order.Ordering == minimizer.Random && value != strconv.FormatUint(order.Seed, 10) {
+			log.Fatalf("Invalid -%s: %s does not match the minimizer index %s, built with k=%d, w=%d and the %s order",
+				f.Name, value, indexFile, index.K(), index.W(), order)
+		}
+	})
+}
+
+func runAddGenome(args []string) {
+	flags := newFlagSet("add-genome")
+	loadReferences := referenceFlags(flags)
+	ambiguity, maxExpansions := ambiguityFlags(flags)
+	indexFile := flags.String("index", "minimizers.mzi", "minimizer index to add the genomes to, created if it does not exist")
+	kFlag := flags.Int("k", 31, fmt.Sprintf("k-mer length of a new index (1-%d)", minimizer.MaxK))
+	wFlag := flags.Int("w", 10, "number of consecutive k-mers in each window of a new index")
+	parseOrder := orderFlags(flags)
+	flags.Parse(args)
+
+	policy, err := ambiguity()
+	if err != nil {
+		log.Fatalf("Invalid -ambiguity: %v", err)
+	}
+	order, err := parseOrder()
+	if err != nil {
+		log.Fatalf("Invalid -order: %v", err)
+	}
+	checkWindowSize(*kFlag, *wFlag)
+	references, err := loadReferences()
+	if err != nil {
+		log.Fatalf("Failed to load references: %v", err)
+	}
+
+	index, err := minimizer.Load(*indexFile)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		// frequent k-mers are those of the genomes the index is created with
+		if order.Ordering == minimizer.Frequency {
+			if err := order.CountFrequent(references, *kFlag, os.Stdout); err != nil {
+				log.Fatalf("Failed to count k-mers: %v", err)
+			}
+		}
+		index = minimizer.NewIndex(*kFlag, *wFlag, order)
+		fmt.Printf("Creating minimizer index %s with k=%d, w=%d and the %s order\n", *indexFile, index.K(), index.W(), index.Order())
+	case err != nil:
+		log.Fatalf("Failed to load minimizer index: %v", err)
+	default:
+		checkIndexFlags(flags, index, *indexFile)
+		fmt.Printf("Loaded minimizer index with k=%d, w=%d and the %s order of %d minimizers in %d genomes from %s\n",
+			index.K(), index.W(), index.Order(), index.Len(), len(index.Genomes()), *indexFile)
+	}
+
+	added := 0
+	for _, reference := range references {
+		// genomes already in the index are not processed again
+		if slices.Contains(index.Genomes(), reference.Name) {
+			fmt.Printf("Skipping genome %s: already in the index\n", reference.Name)
+			continue
+		}
+		if err := index.AddGenome(reference, policy, *maxExpansions, os.Stdout); err != nil {
+			log.Fatalf("Failed to add genome %s: %v", reference.Name, err)
+		}
+		added++
+	}
+	if added == 0 {
+		fmt.Println("No genomes added")
+		return
+	}
+	if err := index.Save(*indexFile); err != nil {
+		log.Fatalf("Failed to write minimizer index: %v", err)
+	}
+	fmt.Printf("Wrote minimizer index with %d minimizers in %d genomes (%d added) to %s\n",
+		index.Len(), len(index.Genomes()), added, *indexFile)
+}
+
+// printDensityReport compares the density of the minimizers of each genome
+// under every order with the 2/(w+1) of a random order
+func printDensityReport(k, w int, orders []*minimizer.Order, genomes []minimizer.GenomeDensity) {
+	expected := minimizer.ExpectedDensity(w)
+	fmt.Printf("\nMinimizer density with k=%d and w=%d; a random order selects 2/(w+1) = %.4f of the k-mers\n", k, w, expected)
+	for _, genome := range genomes {
+		fmt.Printf("\n%s:\n", genome.Genome)
+		fmt.Printf("	%-38s %12s %12s %10s %12s\n", "Order", "K-mers", "Selected", "Density", "vs 2/(w+1)")
+		for o, order := range orders {
+			density := genome.Densities[o]
+			fmt.Printf("	%-38s %12d %12d %10.4f %11.2fx\n", order, density.Kmers, density.Selected,
+				density.Value(), density.Value()/expected)
+		}
+	}
+}
+
+func runMinimizerClassify(args []string) {
+	flags := newFlagSet("minimizer-classify")
+	loadReferences := referenceFlags(flags)
+	readFiles := readsFlag(flags)
+	ambiguity, maxExpansions := ambiguityFlags(flags)
+	kFlag := flags.Int("k", 31, fmt.Sprintf("k-mer length (1-%d)", minimizer.MaxK))
+	wFlag := flags.Int("w", 10, "number of consecutive k-mers in each window")
+	skipMalformed := flags.Bool("skip-malformed", false, "skip malformed FASTQ records instead of stopping at the first one")
+	indexFile := flags.String("index", "", "minimizer index written by add-genome to load instead of building one from the references (its k and w are used)")
+	parseOrder := orderFlags(flags)
+	density := flags.Bool("density", false, "report the minimizer density of every order on each genome against 2/(w+1) and exit")
+	flags.Parse(args)
+
+	policy, err := ambiguity()
+	if err != nil {
+		log.Fatalf("Invalid -ambiguity: %v", err)
+	}
+	order, err := parseOrder()
+	if err != nil {
+		log.Fatalf("Invalid -order: %v", err)
+	}
+	k, w := *kFlag, *wFlag
+	checkWindowSize(k, w)
+	if *density {
+		references, err := loadReferences()
+		if err != nil {
+			log.Fatalf("Failed to load references: %v", err)
+		}
+		orders, genomes, err := minimizer.MeasureDensities(references, k, w, order.Seed, order.Fraction, policy, *maxExpansions)
+		if err != nil {
+			log.Fatalf("Failed to measure minimizer density: %v", err)
+		}
+		printDensityReport(k, w, orders, genomes)
+		return
+	}
+	var references []refs.Reference
+	var index *minimizer.Index
+	if *indexFile != "" {
+		if index, err = minimizer.Load(*indexFile); err != nil {
+			log.Fatalf("Failed to load minimizer index: %v", err)
+		}
+		checkIndexFlags(flags, index, *indexFile)
+		k, w = index.K(), index.W()
+	} else if references, err = loadReferences(); err != nil {
+		log.Fatalf("Failed to load references: %v", err)
+	}
+
+	fmt.Println("\n" + strings.Repeat("=", 80))
+	fmt.Println("Minimizer-Based Classification Report")
+	fmt.Println(strings.Repeat("=", 80))
+
+	if index != nil {
+		fmt.Printf("\nLoaded minimizer index with k=%d, w=%d and the %s order of %d minimizers in %d genomes from %s\n",
+			k, w, index.Order(), index.Len(), len(index.Genomes()), *indexFile)
+	} else {
+		// the frequent k-mers of a frequency order are reported once counted
+		orderName := order.String()
+		if order.Ordering == minimizer.Frequency {
+			orderName = order.Ordering.String()
+		}
+		fmt.Printf("\nBuilding minimizer index with k=%d and w=%d (%s order, ambiguity codes: %s)\n", k, w, orderName, policy)
+		if index, err = minimizer.BuildIndex(references, k, w, order, policy, *maxExpansions, os.Stdout); err != nil {
+			log.Fatalf("Failed to build minimizer index: %v", err)
+		}
+	}
+
+	fmt.Printf("\nClassifying reads using minimizers.\n")
+	readFilter := &minimizer.Filter{Filter: iupac.Filter{Policy: policy, MaxExpansions: *maxExpansions}}
+	readMatches, err := minimizer.ClassifyReads(readFiles(), index, readFilter, *skipMalformed, os.Stdout)
+	if err != nil {
+		log.Fatalf("Failed to classify reads: %v", err)
+	}
+
+	// Calculate statistics
+	orgReadCounts := make(map[string]int)
+	orgMinimizerCounts := make(map[string]int)
+	multipleMatches := 0
+	uniqueMatches := 0
+	noMatches := 0
+
+	for _, match := range readMatches {
+		if len(match.MatchedOrgs) > 1 {
+			multipleMatches++
+		} else if len(match.MatchedOrgs) == 1 {
+			uniqueMatches++
+		} else {
+			noMatches++
+		}
+		for org := range match.MatchedOrgs {
+			orgReadCounts[org]++
+			orgMinimizerCounts[org] += match.MatchedOrgs[org]
+		}
+	}
+
+	// Report results
+	fmt.Printf("\n1. Classification Results:\n")
+	fmt.Printf("    Total sequence reads processed: %d\n", len(readMatches))
+	fmt.Printf("    Matched (minimizer) reads per organism:\n")
+	for _, orgName := range index.Genomes() {
+		fmt.Printf("     %-15s: %d reads, %d minimizer matches\n", orgName, orgReadCounts[orgName], orgMinimizerCounts[orgName])
+	}
+
+	fmt.Printf("\n2. Match Statistics:\n")
+	fmt.Printf("    Reads with unique matches: %d (%.2f%%)\n",
+		uniqueMatches, float64(uniqueMatches)*100/float64(len(readMatches)))
+	fmt.Printf("    Reads with multiple matches: %d (%.2f%%)\n",
+		multipleMatches, float64(multipleMatches)*100/float64(len(readMatches)))
+	fmt.Printf("    Reads with no matches: %d (%.2f%%)\n",
+		noMatches, float64(noMatches)*100/float64(len(readMatches)))
+	fmt.Printf("    Read windows skipped for ambiguity codes: %d\n", readFilter.SkippedWindows)
+
+	fmt.Println("\n" + strings.Repeat("=", 80))
+}
@@ -0,0 +1,135 @@
+// Package binfile holds what the saved automata and indexes have in common: a
+// fixed-size header written last with the CRC-32C checksum of the rest of the
+// file, a file replaced only once completely written, and little-endian arrays
+// used in place after memory-mapping the file.
+package binfile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"hash"
+	"hash/crc32"
+	"io"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// Castagnoli is the table of the CRC-32C checksums stored in file headers
+var Castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// HostLittleEndian reports whether saved arrays can be used in place after mapping
+var HostLittleEndian = func() bool {
+	x := uint16(1)
+	return *(*byte)(unsafe.Pointer(&x)) == 1
+}()
+
+// File writes a file after a header of a fixed size, computing the checksum
+// of everything written. It writes to a temporary file next to the path,
+// which Commit renames to the path once the header is written, so processes
+// that mapped the previous file keep reading it.
+type File struct {
+	*bufio.Writer
+	file     *os.File
+	path     string
+	checksum hash.Hash32
+}
+
+// Create starts writing the file at path, reserving headerSize bytes for the
+// header. The file should be released with Close, which discards it unless
+// Commit succeeded.
+func Create(path string, headerSize int) (*File, error) {
+	file, err := os.Create(path + ".tmp")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := file.Write(make([]byte, headerSize)); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, err
+	}
+	checksum := crc32.New(Castagnoli)
+	return &File{Writer: bufio.NewWriter(io.MultiWriter(file, checksum)), file: file, path: path, checksum: checksum}, nil
+}
+
+// Checksum returns the CRC-32C checksum of everything written after the header
+func (f *File) Checksum() (uint32, error) {
+	if err := f.Flush(); err != nil {
+		return 0, err
+	}
+	return f.checksum.Sum32(), nil
+}
+
+// Commit writes header, a pointer to a fixed-size struct, at the start of the
+// file in little-endian order and renames the file to its path
+func (f *File) Commit(header any) error {
+	if err := f.Flush(); err != nil {
+		return err
+	}
+	if _, err := f.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if err := binary.Write(f.file, binary.LittleEndian, header); err != nil {
+		return err
+	}
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.file.Name(), f.path)
+}
+
+// Close discards the temporary file if Commit did not rename it
+func (f *File) Close() error {
+	f.file.Close()
+	if err := os.Remove(f.file.Name()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Map memory-maps a file read-only; Unmap releases it
+func Map(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() == 0 {
+		// an empty file cannot be mapped
+		return []byte{}, nil
+	}
+	return syscall.Mmap(int(file.Fd()), 0, int(info.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+}
+
+// Unmap releases a file mapped by Map
+func Unmap(data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	return syscall.Munmap(data)
+}
+
+// MappedSlice returns the n values of type T, which must have a fixed size,
+// stored in data at *offset and advances the offset past them. On
+// little-endian hosts the slice shares the memory of data; elsewhere the
+// values are decoded into a new slice.
+func MappedSlice[T any](data []byte, offset *int, n int) []T {
+	size := n * int(unsafe.Sizeof(*new(T)))
+	section := data[*offset : *offset+size]
+	*offset += size
+	if n == 0 {
+		return []T{}
+	}
+	if HostLittleEndian {
+		return unsafe.Slice((*T)(unsafe.Pointer(&section[0])), n)
+	}
+	values := make([]T, n)
+	binary.Read(bytes.NewReader(section), binary.LittleEndian, values)
+	return values
+}
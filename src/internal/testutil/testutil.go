@@ -0,0 +1,80 @@
+// Package testutil generates the random genomes and FASTA files used by the
+// tests and benchmarks, loads the reference genomes the benchmarks can run on
+// instead, and measures the memory held by the structures they compare.
+package testutil
+
+import (
+	"flag"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/Asaad47/BioAlgos-Assignment1/src/refs"
+)
+
+var referenceGenomes = flag.Bool("reference-genomes", false, "run the benchmarks on the genomes of ../references.tsv instead of random ones")
+
+// UseReferenceGenomes reports whether the benchmarks should run on the
+// reference genomes, as asked with -reference-genomes
+func UseReferenceGenomes() bool {
+	return *referenceGenomes
+}
+
+// ReferenceGenomes returns the reference genomes of the manifest of the
+// tasks, src/references.tsv, for benchmarks run from a package directory. It
+// skips the benchmark if a genome is missing, e.g. when data/ is not set up.
+func ReferenceGenomes(tb testing.TB) []refs.Reference {
+	references, err := refs.Load(filepath.Join("..", refs.DefaultManifest))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	for _, reference := range references {
+		if _, err := os.Stat(reference.Path); err != nil {
+			tb.Skipf("reference genome %s is missing, see the README to set up data/: %v", reference.Name, err)
+		}
+	}
+	return references
+}
+
+// HeapGrowth returns how much the live heap grows while build runs, which is
+// the memory held by the value it returns
+func HeapGrowth[T any](build func() T) (int64, T) {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	value := build()
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	return int64(after.HeapAlloc) - int64(before.HeapAlloc), value
+}
+
+// RandomSequence returns n random lowercase bases
+func RandomSequence(random *rand.Rand, n int) string {
+	sequence := make([]byte, n)
+	for i := range sequence {
+		sequence[i] = "acgt"[random.IntN(4)]
+	}
+	return string(sequence)
+}
+
+// WriteFasta writes the records to a FASTA file of the given name in a
+// temporary directory, naming them record1, record2 and so on, with 80 bases
+// per line, and returns its path
+func WriteFasta(tb testing.TB, name string, records ...string) string {
+	var text strings.Builder
+	for i, record := range records {
+		fmt.Fprintf(&text, ">record%d test record\n", i+1)
+		for start := 0; start < len(record); start += 80 {
+			text.WriteString(record[start:min(start+80, len(record))] + "\n")
+		}
+	}
+	path := filepath.Join(tb.TempDir(), name)
+	if err := os.WriteFile(path, []byte(text.String()), 0o644); err != nil {
+		tb.Fatal(err)
+	}
+	return path
+}
@@ -0,0 +1,137 @@
+// Package iupac handles IUPAC nucleotide ambiguity codes (N, R, Y, K, M, ...)
+// under a policy shared by every classifier.
+package iupac
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// Policy decides how ambiguity codes in a sequence are handled
+type Policy int
+
+const (
+	Break    Policy = iota // k-mers containing an ambiguous base are dropped
+	Expand                 // an ambiguous base stands for each base it codes for
+	Wildcard               // an ambiguous base stands for any of a, c, g and t
+)
+
+// DefaultMaxExpansions bounds the number of alternatives an ambiguous k-mer
+// or automaton scan is expanded into
+const DefaultMaxExpansions = 16
+
+var policyNames = []string{"break", "expand", "wildcard"}
+
+func (p Policy) String() string {
+	return policyNames[p]
+}
+
+func ParsePolicy(name string) (Policy, error) {
+	for i, policyName := range policyNames {
+		if name == policyName {
+			return Policy(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown ambiguity policy %q (expected break, expand or wildcard)", name)
+}
+
+// Bases maps a nucleotide character to the set of bases it codes for, as a
+// bit mask with a=1, c=2, g=4 and t=8; characters that are not IUPAC codes are
+// treated like n
+var Bases = func() [256]uint8 {
+	var bases [256]uint8
+	for i := range bases {
+		bases[i] = 1 | 2 | 4 | 8
+	}
+	codes := map[byte]uint8{
+		'a': 1, 'c': 2, 'g': 4, 't': 8,
+		'r': 1 | 4, 'y': 2 | 8, 's': 2 | 4, 'w': 1 | 8, 'k': 4 | 8, 'm': 1 | 2,
+		'b': 2 | 4 | 8, 'd': 1 | 4 | 8, 'h': 1 | 2 | 8, 'v': 1 | 2 | 4, 'n': 1 | 2 | 4 | 8,
+	}
+	for code, set := range codes {
+		bases[code] = set
+		bases[code-'a'+'A'] = set
+	}
+	return bases
+}()
+
+// IsAmbiguous reports whether a character stands for more than one base
+func IsAmbiguous(char byte) bool {
+	return bits.OnesCount8(Bases[char]) != 1
+}
+
+// CountAmbiguous returns the number of ambiguous characters in a sequence
+func CountAmbiguous(sequence []byte) int {
+	count := 0
+	for i := 0; i < len(sequence); i++ {
+		if IsAmbiguous(sequence[i]) {
+			count++
+		}
+	}
+	return count
+}
+
+// Filter applies a Policy to k-mers and counts the k-mers it dropped or
+// expanded
+type Filter struct {
+	Policy        Policy
+	MaxExpansions int // ambiguous k-mers standing for more k-mers than this are dropped
+	Skipped       int // number of ambiguous k-mers dropped
+	Expanded      int // number of k-mers generated from ambiguous k-mers
+}
+
+// Alternatives returns the bases an ambiguity code stands for under the policy
+func (f *Filter) Alternatives(code byte) []byte {
+	if f.Policy == Wildcard {
+		return []byte("acgt")
+	}
+	var bases []byte
+	for i, base := range []byte("acgt") {
+		if Bases[code]&(1<<i) != 0 {
+			bases = append(bases, base)
+		}
+	}
+	return bases
+}
+
+// Resolve calls fn with each unambiguous k-mer that kmer stands for under the
+// policy: kmer itself if it has no ambiguity codes, none if it is dropped
+func (f *Filter) Resolve(kmer string, fn func(kmer string)) {
+	expansions := 1
+	for i := 0; i < len(kmer); i++ {
+		if !IsAmbiguous(kmer[i]) {
+			continue
+		}
+		if f.Policy == Break {
+			f.Skipped++
+			return
+		}
+		expansions *= len(f.Alternatives(kmer[i]))
+		if expansions > f.MaxExpansions {
+			f.Skipped++
+			return
+		}
+	}
+	if expansions == 1 {
+		fn(kmer)
+		return
+	}
+
+	f.Expanded += expansions
+	variant := []byte(kmer)
+	var expand func(i int)
+	expand = func(i int) {
+		for i < len(variant) && !IsAmbiguous(kmer[i]) {
+			i++
+		}
+		if i == len(variant) {
+			fn(string(variant))
+			return
+		}
+		for _, base := range f.Alternatives(kmer[i]) {
+			variant[i] = base
+			expand(i + 1)
+		}
+	}
+	expand(0)
+}
@@ -0,0 +1,114 @@
+package kmer
+
+import (
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	"github.com/Asaad47/BioAlgos-Assignment1/src/internal/testutil"
+	"github.com/Asaad47/BioAlgos-Assignment1/src/iupac"
+	"github.com/Asaad47/BioAlgos-Assignment1/src/refs"
+	"github.com/Asaad47/BioAlgos-Assignment1/src/seqio"
+)
+
+// kmerStats tracks the occurrence of a k-mer across genomes in the original
+// string-keyed index
+type kmerStats struct {
+	occurrences map[string]int // maps genome name to count
+	totalCount  int            // total occurrences across all genomes
+}
+
+// buildStringIndex is the original k-mer index keyed on k-mer strings, kept
+// only as the baseline of the benchmarks
+func buildStringIndex(references []refs.Reference, k int, policy iupac.Policy, maxExpansions int) (map[string]*kmerStats, error) {
+	kmerIndex := make(map[string]*kmerStats)
+	for _, reference := range references {
+		fastaFile, err := seqio.Open(reference.Path)
+		if err != nil {
+			return nil, err
+		}
+		defer fastaFile.Close()
+
+		orgName := reference.Name
+		filter := iupac.Filter{Policy: policy, MaxExpansions: maxExpansions}
+		addKmer := func(kmer string) {
+			if _, exists := kmerIndex[kmer]; !exists {
+				kmerIndex[kmer] = &kmerStats{
+					occurrences: make(map[string]int),
+				}
+			}
+			kmerIndex[kmer].occurrences[orgName]++
+			kmerIndex[kmer].totalCount++
+		}
+
+		reader := seqio.NewFastaReader(fastaFile)
+		for {
+			record, err := reader.ReadRecord()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", reference.Path, err)
+			}
+			sequence := strings.ToLower(string(record.Sequence))
+			for i := 0; i <= len(sequence)-k; i++ {
+				filter.Resolve(sequence[i:i+k], addKmer)
+			}
+		}
+	}
+	return kmerIndex, nil
+}
+
+// writeReferences writes each genome to a FASTA file and returns them as
+// references named genome1, genome2 and so on
+func writeReferences(tb testing.TB, genomes ...string) []refs.Reference {
+	references := make([]refs.Reference, len(genomes))
+	for i, genome := range genomes {
+		name := fmt.Sprintf("genome%d", i+1)
+		references[i] = refs.Reference{Name: name, Path: testutil.WriteFasta(tb, name+".fna", genome)}
+	}
+	return references
+}
+
+// BenchmarkBuildIndex compares the build time, memory and allocations of the
+// string-keyed k-mer map with the packed Index, on three random 256 kbp genomes
+func BenchmarkBuildIndex(b *testing.B) {
+	random := rand.New(rand.NewPCG(1, 2))
+	references := writeReferences(b, testutil.RandomSequence(random, 1<<18), testutil.RandomSequence(random, 1<<18), testutil.RandomSequence(random, 1<<18))
+	for _, k := range []int{31, 63} {
+		b.Run(fmt.Sprintf("string-map/k=%d", k), func(b *testing.B) {
+			build := func() map[string]*kmerStats {
+				kmerIndex, err := buildStringIndex(references, k, iupac.Break, iupac.DefaultMaxExpansions)
+				if err != nil {
+					b.Fatal(err)
+				}
+				return kmerIndex
+			}
+			memory, _ := testutil.HeapGrowth(build)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				build()
+			}
+			b.ReportMetric(float64(memory)/(1<<20), "MB")
+		})
+		b.Run(fmt.Sprintf("packed/k=%d", k), func(b *testing.B) {
+			build := func() *Index {
+				kmerIndex, _, err := BuildIndex(references, k, false, iupac.Break, iupac.DefaultMaxExpansions, io.Discard)
+				if err != nil {
+					b.Fatal(err)
+				}
+				return kmerIndex
+			}
+			memory, _ := testutil.HeapGrowth(build)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				build()
+			}
+			b.ReportMetric(float64(memory)/(1<<20), "MB")
+		})
+	}
+}
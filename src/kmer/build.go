@@ -0,0 +1,74 @@
+package kmer
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Asaad47/BioAlgos-Assignment1/src/iupac"
+	"github.com/Asaad47/BioAlgos-Assignment1/src/refs"
+	"github.com/Asaad47/BioAlgos-Assignment1/src/seqio"
+)
+
+// BuildIndex indexes the packed (optionally canonical) k-mers of every
+// genome and returns the index with the total genome length, writing progress
+// to the given writer
+func BuildIndex(references []refs.Reference, k int, canonical bool, policy iupac.Policy, maxExpansions int, progress io.Writer) (*Index, int, error) {
+	genomes := refs.Names(references)
+	kmerIndex := NewIndex(k, canonical, genomes)
+	totalGenomeLength := 0
+
+	for genome, reference := range references {
+		fastaFile, err := seqio.Open(reference.Path)
+		if err != nil {
+			return nil, 0, err
+		}
+		defer fastaFile.Close()
+
+		orgName := genomes[genome]
+		fmt.Fprintf(progress, "Processing genome: %s\n", orgName)
+		genomeLength := 0
+		ambiguous := 0
+		filter := iupac.Filter{Policy: policy, MaxExpansions: maxExpansions}
+		roller := newKmerRoller(k, canonical, &filter)
+
+		reader := seqio.NewFastaReader(fastaFile)
+		buffer := make([]byte, 1<<16)
+		for reader.Next() {
+			// records are streamed, so no k-mer spans two records
+			roller.reset()
+			for {
+				n, err := reader.Read(buffer)
+				genomeLength += n
+				ambiguous += iupac.CountAmbiguous(buffer[:n])
+				roller.feed(buffer[:n], func(kmer Kmer) {
+					kmerIndex.add(kmer, genome)
+				})
+				if err != nil {
+					break
+				}
+			}
+		}
+		if err := reader.Err(); err != nil {
+			return nil, 0, fmt.Errorf("%s: %w", reference.Path, err)
+		}
+		fmt.Fprintf(progress, "Genome length (%s): %d\n", orgName, genomeLength)
+		fmt.Fprintf(progress, "Ambiguity codes (%s): %d bases, %d k-mers skipped, %d k-mers from expansions\n",
+			orgName, ambiguous, filter.Skipped, filter.Expanded)
+		totalGenomeLength += genomeLength
+	}
+
+	return kmerIndex, totalGenomeLength, nil
+}
+
+// KmersPerGenome returns the number of distinct k-mers of each genome in the index
+func KmersPerGenome(kmerIndex *Index) map[string]int {
+	kmers := make(map[string]int)
+	for row := 0; row < kmerIndex.Len(); row++ {
+		for genome, name := range kmerIndex.genomes {
+			if kmerIndex.count(int32(row), genome) > 0 {
+				kmers[name]++
+			}
+		}
+	}
+	return kmers
+}
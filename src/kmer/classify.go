@@ -0,0 +1,224 @@
+package kmer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Asaad47/BioAlgos-Assignment1/src/iupac"
+	"github.com/Asaad47/BioAlgos-Assignment1/src/seqio"
+)
+
+// ReadMatch tracks matches for a single sequence read
+type ReadMatch struct {
+	ReadID       string
+	MatchedOrgs  map[string]int // maps organism to number of k-mer matches
+	TotalMatches int            // total number of k-mer matches across all organisms
+	TaxonHits    map[int]int    // maps taxon to number of k-mers labelled with it, if k-mers are labelled
+}
+
+func extractKmers(sequence string, k int, canonical bool, filter *iupac.Filter) []Kmer {
+	sequence = strings.TrimSpace(sequence)
+	kmers := make([]Kmer, 0, max(0, len(sequence)-k+1))
+	newKmerRoller(k, canonical, filter).feed([]byte(sequence), func(kmer Kmer) {
+		kmers = append(kmers, kmer)
+	})
+	return kmers
+}
+
+// MatchRead checks each k-mer of a read against the index. With kmerTaxa, the
+// taxon of each k-mer by row, it also counts the hits of each taxon.
+func MatchRead(readID string, sequence []byte, kmerIndex *Index, filter *iupac.Filter, kmerTaxa []int32) *ReadMatch {
+	match := &ReadMatch{
+		ReadID:      readID,
+		MatchedOrgs: make(map[string]int),
+	}
+	if kmerTaxa != nil {
+		match.TaxonHits = make(map[int]int)
+	}
+	for _, kmer := range extractKmers(string(sequence), kmerIndex.k, kmerIndex.canonical, filter) {
+		row, exists := kmerIndex.row(kmer)
+		if !exists {
+			continue
+		}
+		if kmerTaxa != nil {
+			match.TaxonHits[int(kmerTaxa[row])]++
+		}
+		// add matches for each organism
+		for genome := range kmerIndex.genomes {
+			if count := kmerIndex.count(row, genome); count > 0 {
+				organism := kmerIndex.genomes[genome]
+				match.MatchedOrgs[organism] += int(count)
+				match.TotalMatches += int(count)
+			}
+		}
+	}
+	return match
+}
+
+// ClassifyReads matches every read of the read files against the index, keyed
+// by read ID. Reading stops at the first malformed record unless skipMalformed
+// is set, in which case the number of records skipped is written to progress.
+func ClassifyReads(readFiles []string, kmerIndex *Index, filter *iupac.Filter, kmerTaxa []int32, skipMalformed bool, progress io.Writer) (map[string]*ReadMatch, error) {
+	readMatches := make(map[string]*ReadMatch)
+
+	for _, readFile := range readFiles {
+		file, err := seqio.Open(readFile)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		reader := seqio.NewFastqReader(file)
+		reader.SkipMalformed = skipMalformed
+		for {
+			record, err := reader.ReadRecord()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", readFile, err)
+			}
+			readID := record.Header() + "_" + readFile
+			readMatches[readID] = MatchRead(readID, record.Sequence, kmerIndex, filter, kmerTaxa)
+		}
+		if reader.Skipped() > 0 {
+			fmt.Fprintf(progress, "Skipped %d malformed reads in %s (first at %v)\n", reader.Skipped(), readFile, reader.FirstSkipped())
+		}
+	}
+
+	return readMatches, nil
+}
+
+// PairStats counts read pairs by how the organisms matched by their mates agree
+type PairStats struct {
+	Concordant int // both mates matched, with at least one organism in common
+	Discordant int // both mates matched, but no organism in common
+	SingleMate int // only one mate matched
+	Unmatched  int // neither mate matched
+	Orphans    int // reads whose mate is missing from the other file
+}
+
+// Pairs returns the number of read pairs whose mates were both found
+func (stats *PairStats) Pairs() int {
+	return stats.Concordant + stats.Discordant + stats.SingleMate + stats.Unmatched
+}
+
+func (stats *PairStats) add(mate1, mate2 *ReadMatch) {
+	switch {
+	case mate1.TotalMatches == 0 && mate2.TotalMatches == 0:
+		stats.Unmatched++
+	case mate1.TotalMatches == 0 || mate2.TotalMatches == 0:
+		stats.SingleMate++
+	default:
+		for org := range mate1.MatchedOrgs {
+			if mate2.MatchedOrgs[org] > 0 {
+				stats.Concordant++
+				return
+			}
+		}
+		stats.Discordant++
+	}
+}
+
+// fragmentName returns the name shared by both mates of a read pair, the read
+// name without its /1 or /2 suffix
+func fragmentName(readName string) string {
+	if strings.HasSuffix(readName, "/1") || strings.HasSuffix(readName, "/2") {
+		return readName[:len(readName)-2]
+	}
+	return readName
+}
+
+// combineMates pools the k-mer matches of both mates of a fragment
+func combineMates(fragmentID string, mate1, mate2 *ReadMatch) *ReadMatch {
+	fragment := &ReadMatch{
+		ReadID:       fragmentID,
+		MatchedOrgs:  make(map[string]int),
+		TotalMatches: mate1.TotalMatches + mate2.TotalMatches,
+	}
+	if mate1.TaxonHits != nil {
+		fragment.TaxonHits = make(map[int]int)
+	}
+	for _, mate := range []*ReadMatch{mate1, mate2} {
+		for org, count := range mate.MatchedOrgs {
+			fragment.MatchedOrgs[org] += count
+		}
+		for taxon, hits := range mate.TaxonHits {
+			fragment.TaxonHits[taxon] += hits
+		}
+	}
+	return fragment
+}
+
+// ClassifyPairs classifies the fragments of paired-end reads, given as pairs of
+// R1 and R2 files, on the k-mer matches of both mates. Mates are paired by
+// read name and may appear in a different order in the two files; reads whose
+// mate is never found are classified on their own. Malformed records are
+// handled as by ClassifyReads.
+func ClassifyPairs(readFilePairs [][2]string, kmerIndex *Index, filter *iupac.Filter, kmerTaxa []int32, skipMalformed bool, progress io.Writer) (map[string]*ReadMatch, PairStats, error) {
+	fragmentMatches := make(map[string]*ReadMatch)
+	var stats PairStats
+
+	for _, readFiles := range readFilePairs {
+		var readers [2]*seqio.FastqReader
+		for mate, readFile := range readFiles {
+			file, err := seqio.Open(readFile)
+			if err != nil {
+				return nil, PairStats{}, err
+			}
+			defer file.Close()
+			readers[mate] = seqio.NewFastqReader(file)
+			readers[mate].SkipMalformed = skipMalformed
+		}
+
+		// mates read from one file whose mate has not been read from the other yet
+		pending := [2]map[string]*ReadMatch{make(map[string]*ReadMatch), make(map[string]*ReadMatch)}
+		var done [2]bool
+		for !done[0] || !done[1] {
+			for mate, reader := range readers {
+				if done[mate] {
+					continue
+				}
+				record, err := reader.ReadRecord()
+				if err == io.EOF {
+					done[mate] = true
+					continue
+				}
+				if err != nil {
+					return nil, PairStats{}, fmt.Errorf("%s: %w", readFiles[mate], err)
+				}
+				name := fragmentName(record.Name)
+				match := MatchRead(record.Header()+"_"+readFiles[mate], record.Sequence, kmerIndex, filter, kmerTaxa)
+				other, found := pending[1-mate][name]
+				if !found {
+					pending[mate][name] = match
+					continue
+				}
+				delete(pending[1-mate], name)
+
+				mate1, mate2 := match, other
+				if mate == 1 {
+					mate1, mate2 = other, match
+				}
+				stats.add(mate1, mate2)
+				fragmentID := name + "_" + readFiles[0]
+				fragmentMatches[fragmentID] = combineMates(fragmentID, mate1, mate2)
+			}
+		}
+
+		for _, orphans := range pending {
+			for _, match := range orphans {
+				fragmentMatches[match.ReadID] = match
+				stats.Orphans++
+			}
+		}
+		for mate, reader := range readers {
+			if reader.Skipped() > 0 {
+				fmt.Fprintf(progress, "Skipped %d malformed reads in %s (first at %v)\n", reader.Skipped(), readFiles[mate], reader.FirstSkipped())
+			}
+		}
+	}
+
+	return fragmentMatches, stats, nil
+}
@@ -0,0 +1,122 @@
+package kmer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"maps"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Asaad47/BioAlgos-Assignment1/src/internal/testutil"
+	"github.com/Asaad47/BioAlgos-Assignment1/src/iupac"
+)
+
+// writeFastq writes reads, given as name and sequence, to a FASTQ file of the
+// given name in a temporary directory and returns its path
+func writeFastq(t *testing.T, name string, reads ...[2]string) string {
+	var text strings.Builder
+	for _, read := range reads {
+		fmt.Fprintf(&text, "@%s\n%s\n+\n%s\n", read[0], read[1], strings.Repeat("I", len(read[1])))
+	}
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(text.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestClassifyPairs(t *testing.T) {
+	random := rand.New(rand.NewPCG(1, 2))
+	genome1, genome2 := testutil.RandomSequence(random, 5000), testutil.RandomSequence(random, 5000)
+	// canonical k-mers, so that R2 mates from the reverse strand match
+	kmerIndex, _, err := BuildIndex(writeReferences(t, genome1, genome2), 21, true, iupac.Break, iupac.DefaultMaxExpansions, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	noise := func() string { return testutil.RandomSequence(random, 100) }
+
+	// mates are listed in another order in R2, some without a /1 or /2 suffix
+	r1 := writeFastq(t, "reads_R1.fastq",
+		[2]string{"concordant/1", genome1[100:200]},
+		[2]string{"discordant/1", genome1[1000:1100]},
+		[2]string{"single/1", noise()},
+		[2]string{"unmatched/1", noise()},
+		[2]string{"plain", genome2[300:400]},
+		[2]string{"orphan1/1", genome1[2000:2100]},
+	)
+	r2 := writeFastq(t, "reads_R2.fastq",
+		[2]string{"orphan2/2", noise()},
+		[2]string{"plain", reverseComplement(genome2[500:600])},
+		[2]string{"unmatched/2", noise()},
+		[2]string{"discordant/2", reverseComplement(genome2[1000:1100])},
+		[2]string{"concordant/2", reverseComplement(genome1[400:500])},
+		[2]string{"single/2", genome2[4000:4100]},
+	)
+
+	var progress bytes.Buffer
+	matches, stats, err := ClassifyPairs([][2]string{{r1, r2}}, kmerIndex, &iupac.Filter{Policy: iupac.Break, MaxExpansions: iupac.DefaultMaxExpansions}, nil, false, &progress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := PairStats{Concordant: 2, Discordant: 1, SingleMate: 1, Unmatched: 1, Orphans: 2}
+	if stats != want || stats.Pairs() != 5 {
+		t.Errorf("pair statistics are %+v, want %+v", stats, want)
+	}
+
+	fragments := map[string]map[string]int{
+		"concordant_" + r1: {"genome1": 160},
+		"discordant_" + r1: {"genome1": 80, "genome2": 80},
+		"single_" + r1:     {"genome2": 80},
+		"unmatched_" + r1:  {},
+		"plain_" + r1:      {"genome2": 160},
+		"orphan1/1_" + r1:  {"genome1": 80},
+		"orphan2/2_" + r2:  {},
+	}
+	if len(matches) != len(fragments) {
+		t.Errorf("classified %d fragments, want %d", len(matches), len(fragments))
+	}
+	for id, orgs := range fragments {
+		match, found := matches[id]
+		if !found {
+			t.Errorf("fragment %s missing", id)
+			continue
+		}
+		if match.ReadID != id || !maps.Equal(match.MatchedOrgs, orgs) {
+			t.Errorf("fragment %s matched %v as %s, want %v", id, match.MatchedOrgs, match.ReadID, orgs)
+		}
+	}
+	if progress.Len() != 0 {
+		t.Errorf("wrote %q for well-formed reads", progress.String())
+	}
+}
+
+func TestClassifyPairsMalformed(t *testing.T) {
+	random := rand.New(rand.NewPCG(1, 2))
+	genome := testutil.RandomSequence(random, 2000)
+	kmerIndex, _, err := BuildIndex(writeReferences(t, genome), 21, false, iupac.Break, iupac.DefaultMaxExpansions, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r1 := writeFastq(t, "reads_R1.fastq", [2]string{"a/1", genome[:100]}, [2]string{"b/1", genome[500:600]})
+	r2 := filepath.Join(t.TempDir(), "reads_R2.fastq")
+	if err := os.WriteFile(r2, []byte("@a/2\nACGT\n+\nII\n@b/2\nACGT\n+\nIIII\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	filter := &iupac.Filter{Policy: iupac.Break, MaxExpansions: iupac.DefaultMaxExpansions}
+
+	if _, _, err := ClassifyPairs([][2]string{{r1, r2}}, kmerIndex, filter, nil, false, io.Discard); err == nil {
+		t.Error("classified pairs with a malformed mate without an error")
+	}
+	var progress bytes.Buffer
+	_, stats, err := ClassifyPairs([][2]string{{r1, r2}}, kmerIndex, filter, nil, true, &progress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Pairs() != 1 || stats.Orphans != 1 || !strings.Contains(progress.String(), "Skipped 1 malformed reads in "+r2) {
+		t.Errorf("skipping the malformed mate gave %+v and reported %q", stats, progress.String())
+	}
+}
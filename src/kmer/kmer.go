@@ -0,0 +1,296 @@
+// Package kmer indexes the k-mers of reference genomes, packed at 2 bits per
+// base, and classifies reads by the genomes sharing their k-mers. With a
+// taxonomy, k-mers are labelled with the LCA of their genomes and reads are
+// assigned to taxa as Kraken does.
+package kmer
+
+import (
+	"math"
+	"math/bits"
+	"slices"
+
+	"github.com/Asaad47/BioAlgos-Assignment1/src/iupac"
+)
+
+const (
+	MaxSmallK = 32 // longest k-mer that fits in one uint64 at 2 bits per base
+	MaxK      = 64 // longest k-mer that fits in a Kmer
+)
+
+// Kmer is a k-mer packed at 2 bits per base (a=0, c=1, g=2, t=3) into two
+// words, the last base in the lowest bits of lo. K-mers of up to MaxSmallK
+// bases fit entirely in lo.
+type Kmer struct {
+	hi, lo uint64
+}
+
+// kmerMask returns the mask keeping the 2k lowest bits of a Kmer
+func kmerMask(k int) Kmer {
+	if k <= MaxSmallK {
+		return Kmer{lo: math.MaxUint64 >> (64 - 2*k)}
+	}
+	return Kmer{hi: math.MaxUint64 >> (128 - 2*k), lo: math.MaxUint64}
+}
+
+// less orders k-mers by their encoding, which is also their lexicographic order
+func (kmer Kmer) less(other Kmer) bool {
+	return kmer.hi < other.hi || kmer.hi == other.hi && kmer.lo < other.lo
+}
+
+// compareKmers orders k-mers like less, for sorting and binary search
+func compareKmers(a, b Kmer) int {
+	switch {
+	case a.less(b):
+		return -1
+	case b.less(a):
+		return 1
+	}
+	return 0
+}
+
+// Index counts the occurrences of packed k-mers (k <= MaxK) in each genome.
+// Every distinct k-mer owns a row of per-genome counts in a single flat slice,
+// so the index holds no per-k-mer strings or maps. K-mers of up to MaxSmallK
+// bases are keyed on a single word.
+//
+// An index loaded by Load is read-only: its k-mers are sorted and found by
+// binary search, and its counts are stored one column per genome.
+type Index struct {
+	k         int
+	canonical bool             // k-mers are stored as the smaller of their two strands' encodings
+	genomes   []string         // genome names, one column of counts each
+	kmers     map[uint64]int32 // packed k-mer -> row of counts, for k <= MaxSmallK
+	wide      map[Kmer]int32   // packed k-mer -> row of counts, for k > MaxSmallK
+	counts    []uint32         // count of the k-mer in row r for genome g at r*len(genomes)+g
+
+	sortedKmers []uint64   // k-mers of a loaded index in increasing order, the row being the position
+	sortedWide  []Kmer     // same for k > MaxSmallK
+	columns     [][]uint32 // counts of a loaded index, columns[g][r] for the k-mer in row r and genome g
+	mapped      []byte     // file backing a loaded index
+}
+
+func NewIndex(k int, canonical bool, genomes []string) *Index {
+	idx := &Index{k: k, canonical: canonical, genomes: genomes}
+	if k <= MaxSmallK {
+		idx.kmers = make(map[uint64]int32)
+	} else {
+		idx.wide = make(map[Kmer]int32)
+	}
+	return idx
+}
+
+// row returns the row of counts of a packed k-mer
+func (idx *Index) row(kmer Kmer) (int32, bool) {
+	if idx.columns != nil {
+		if idx.k > MaxSmallK {
+			row, exists := slices.BinarySearchFunc(idx.sortedWide, kmer, compareKmers)
+			return int32(row), exists
+		}
+		row, exists := slices.BinarySearch(idx.sortedKmers, kmer.lo)
+		return int32(row), exists
+	}
+	if idx.wide != nil {
+		row, exists := idx.wide[kmer]
+		return row, exists
+	}
+	row, exists := idx.kmers[kmer.lo]
+	return row, exists
+}
+
+// add counts one occurrence of a packed k-mer in the genome with the given number
+func (idx *Index) add(kmer Kmer, genome int) {
+	row, exists := idx.row(kmer)
+	if !exists {
+		row = int32(idx.Len())
+		if idx.wide != nil {
+			idx.wide[kmer] = row
+		} else {
+			idx.kmers[kmer.lo] = row
+		}
+		for range idx.genomes {
+			idx.counts = append(idx.counts, 0)
+		}
+	}
+	idx.counts[int(row)*len(idx.genomes)+genome]++
+}
+
+// count returns the count of the k-mer in a row for a genome
+func (idx *Index) count(row int32, genome int) uint32 {
+	if idx.columns != nil {
+		return idx.columns[genome][row]
+	}
+	return idx.counts[int(row)*len(idx.genomes)+genome]
+}
+
+// K returns the k-mer length of the index
+func (idx *Index) K() int {
+	return idx.k
+}
+
+// Canonical reports whether the index holds canonical k-mers
+func (idx *Index) Canonical() bool {
+	return idx.canonical
+}
+
+// Genomes returns the names of the indexed genomes
+func (idx *Index) Genomes() []string {
+	return idx.genomes
+}
+
+// Len returns the number of distinct k-mers in the index
+func (idx *Index) Len() int {
+	return len(idx.kmers) + len(idx.wide) + len(idx.sortedKmers) + len(idx.sortedWide)
+}
+
+// kmerRoller iterates over the packed k-mers of a sequence as the sequence is
+// fed to it, rolling each k-mer into the next with a shift and a mask.
+// Sequences may be fed in pieces, e.g. one FASTA line at a time, and yield the
+// same k-mers however they are split.
+//
+// The reverse complement of each k-mer is rolled along in the opposite
+// direction, so canonical k-mers cost no more than forward ones.
+type kmerRoller struct {
+	k         int
+	mask      Kmer
+	canonical bool // report the smaller of a k-mer and its reverse complement
+	filter    *iupac.Filter
+	kmers     []Kmer // current k-mer; several while it spans expanded ambiguity codes
+	reverse   []Kmer // reverse complements of kmers
+	length    int    // number of bases rolled in since the last restart
+	fed       int    // number of bases fed since the last reset
+	window    []byte // last k bases fed, indexed by fed modulo k
+}
+
+func newKmerRoller(k int, canonical bool, filter *iupac.Filter) *kmerRoller {
+	return &kmerRoller{
+		k:         k,
+		mask:      kmerMask(k),
+		canonical: canonical,
+		filter:    filter,
+		kmers:     []Kmer{{}},
+		reverse:   []Kmer{{}},
+		window:    make([]byte, k),
+	}
+}
+
+// reset starts a new sequence
+func (r *kmerRoller) reset() {
+	r.restart()
+	r.fed = 0
+}
+
+// restart drops the bases rolled in so far, e.g. at an ambiguity code
+func (r *kmerRoller) restart() {
+	r.kmers = append(r.kmers[:0], Kmer{})
+	r.reverse = append(r.reverse[:0], Kmer{})
+	r.length = 0
+}
+
+// feed rolls the bases of sequence in and calls fn with every complete
+// k-mer, applying the ambiguity policy of the kmerRoller's filter
+func (r *kmerRoller) feed(sequence []byte, fn func(kmer Kmer)) {
+	for i := 0; i < len(sequence); i++ {
+		bases := r.alternatives(sequence[i])
+		r.window[r.fed%r.k] = sequence[i]
+		r.fed++
+		if bits.OnesCount8(bases) == 1 && len(r.kmers) == 1 {
+			r.kmers[0], r.reverse[0] = r.roll(r.kmers[0], r.reverse[0], bits.TrailingZeros8(bases))
+			r.length++
+		} else if !r.expand(bases) {
+			r.rebuild()
+		}
+
+		if r.length < r.k {
+			if r.fed >= r.k {
+				r.filter.Skipped++
+			}
+			continue
+		}
+		if len(r.kmers) > 1 {
+			r.filter.Expanded += len(r.kmers)
+		}
+		for j, kmer := range r.kmers {
+			if r.canonical && r.reverse[j].less(kmer) {
+				kmer = r.reverse[j]
+			}
+			fn(kmer)
+		}
+	}
+}
+
+// roll appends a base (0-3) to a k-mer and prepends its complement to the
+// reverse complement of the k-mer
+func (r *kmerRoller) roll(kmer, reverse Kmer, symbol int) (Kmer, Kmer) {
+	kmer = Kmer{
+		hi: (kmer.hi<<2 | kmer.lo>>62) & r.mask.hi,
+		lo: (kmer.lo<<2 | uint64(symbol)) & r.mask.lo,
+	}
+	reverse = Kmer{hi: reverse.hi >> 2, lo: reverse.lo>>2 | reverse.hi<<62}
+	if shift := 2 * (r.k - 1); shift < 64 {
+		reverse.lo |= uint64(3-symbol) << shift
+	} else {
+		reverse.hi |= uint64(3-symbol) << (shift - 64)
+	}
+	return kmer, reverse
+}
+
+// alternatives returns the bases a character stands for under the ambiguity
+// policy, none if the policy drops it
+func (r *kmerRoller) alternatives(char byte) uint8 {
+	bases := iupac.Bases[char]
+	if bits.OnesCount8(bases) != 1 {
+		switch r.filter.Policy {
+		case iupac.Break:
+			return 0
+		case iupac.Wildcard:
+			return 1 | 2 | 4 | 8
+		}
+	}
+	return bases
+}
+
+// rebuild restarts from the longest run of bases fed last that expands to no
+// more than the filter's maxExpansions k-mers. The k-mers ending in the bases
+// before it are all dropped, but later ones can still be resolved.
+func (r *kmerRoller) rebuild() {
+	start, expansions := r.fed, 1
+	for start > r.fed-min(r.length+1, r.k) {
+		expansions *= bits.OnesCount8(r.alternatives(r.window[(start-1)%r.k]))
+		if expansions == 0 || expansions > r.filter.MaxExpansions {
+			break
+		}
+		start--
+	}
+	r.restart()
+	for ; start < r.fed; start++ {
+		r.expand(r.alternatives(r.window[start%r.k]))
+	}
+}
+
+// expand rolls a set of bases into every current k-mer. It reports false if
+// the set is empty or the number of k-mers would exceed the filter's
+// maxExpansions.
+func (r *kmerRoller) expand(bases uint8) bool {
+	if bases == 0 {
+		return false
+	}
+	var expanded, reverse []Kmer
+	for j, kmer := range r.kmers {
+		for symbol := 0; symbol < 4; symbol++ {
+			if bases&(1<<symbol) == 0 {
+				continue
+			}
+			next, nextReverse := r.roll(kmer, r.reverse[j], symbol)
+			if !slices.Contains(expanded, next) {
+				expanded = append(expanded, next)
+				reverse = append(reverse, nextReverse)
+			}
+		}
+	}
+	if len(expanded) > r.filter.MaxExpansions {
+		return false
+	}
+	r.kmers, r.reverse = expanded, reverse
+	r.length++
+	return true
+}
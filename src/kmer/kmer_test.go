@@ -0,0 +1,105 @@
+package kmer
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Asaad47/BioAlgos-Assignment1/src/iupac"
+)
+
+// packKmer packs a k-mer of lowercase a, c, g and t the naive way, one base at
+// a time over both words
+func packKmer(kmer string) Kmer {
+	var packed Kmer
+	for i := 0; i < len(kmer); i++ {
+		code := uint64(strings.IndexByte("acgt", kmer[i]))
+		packed = Kmer{hi: packed.hi<<2 | packed.lo>>62, lo: packed.lo<<2 | code}
+	}
+	return packed
+}
+
+// naiveKmers resolves every k-mer of a sequence with the filter and packs the
+// k-mers it stands for, or the smaller of each and its reverse complement
+func naiveKmers(sequence string, k int, canonical bool, filter *iupac.Filter) []Kmer {
+	var kmers []Kmer
+	for i := 0; i+k <= len(sequence); i++ {
+		filter.Resolve(sequence[i:i+k], func(variant string) {
+			kmer := packKmer(variant)
+			if reverse := packKmer(reverseComplement(variant)); canonical && reverse.less(kmer) {
+				kmer = reverse
+			}
+			kmers = append(kmers, kmer)
+		})
+	}
+	return kmers
+}
+
+// reverseComplement returns the reverse complement of lowercase bases
+func reverseComplement(sequence string) string {
+	complement := make([]byte, len(sequence))
+	for i := 0; i < len(sequence); i++ {
+		complement[len(sequence)-1-i] = "tgca"[strings.IndexByte("acgt", sequence[i])]
+	}
+	return string(complement)
+}
+
+// ambiguousSequence returns n random lowercase bases, about one in a hundred
+// being an ambiguity code instead
+func ambiguousSequence(random *rand.Rand, n int) string {
+	sequence := make([]byte, n)
+	for i := range sequence {
+		sequence[i] = "acgt"[random.IntN(4)]
+		if random.IntN(100) == 0 {
+			sequence[i] = "nrykm"[random.IntN(5)]
+		}
+	}
+	return string(sequence)
+}
+
+// TestKmerRoller checks the rolled k-mers against packing every k-mer from
+// scratch, with the sequence fed whole or in random pieces
+func TestKmerRoller(t *testing.T) {
+	random := rand.New(rand.NewPCG(1, 2))
+	sequence := ambiguousSequence(random, 3000)
+	for _, k := range []int{1, 15, 32, 33, 63, 64} {
+		for _, canonical := range []bool{false, true} {
+			for _, policy := range []iupac.Policy{iupac.Break, iupac.Expand, iupac.Wildcard} {
+				t.Run(fmt.Sprintf("k=%d/canonical=%v/%s", k, canonical, policy), func(t *testing.T) {
+					naive := iupac.Filter{Policy: policy, MaxExpansions: iupac.DefaultMaxExpansions}
+					want := naiveKmers(sequence, k, canonical, &naive)
+
+					filter := iupac.Filter{Policy: policy, MaxExpansions: iupac.DefaultMaxExpansions}
+					whole := extractKmers(sequence, k, canonical, &filter)
+					var pieces []Kmer
+					roller := newKmerRoller(k, canonical, &iupac.Filter{Policy: policy, MaxExpansions: iupac.DefaultMaxExpansions})
+					for start := 0; start < len(sequence); {
+						end := min(start+1+random.IntN(100), len(sequence))
+						roller.feed([]byte(sequence[start:end]), func(kmer Kmer) {
+							pieces = append(pieces, kmer)
+						})
+						start = end
+					}
+
+					if policy != iupac.Break {
+						// the k-mers an ambiguous k-mer expands to may come in another order
+						for _, kmers := range [][]Kmer{want, whole, pieces} {
+							slices.SortFunc(kmers, compareKmers)
+						}
+					}
+					if !slices.Equal(whole, want) {
+						t.Errorf("rolled %d k-mers, want %d", len(whole), len(want))
+					}
+					if !slices.Equal(pieces, want) {
+						t.Errorf("rolled %d k-mers from pieces, want %d", len(pieces), len(want))
+					}
+					if filter.Skipped != naive.Skipped || filter.Expanded != naive.Expanded {
+						t.Errorf("skipped %d and expanded %d k-mers, want %d and %d", filter.Skipped, filter.Expanded, naive.Skipped, naive.Expanded)
+					}
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package kmer
+
+import (
+	"fmt"
+
+	"github.com/Asaad47/BioAlgos-Assignment1/src/refs"
+	"github.com/Asaad47/BioAlgos-Assignment1/src/taxonomy"
+)
+
+// ClassifyTaxon assigns a read to a taxon from the number of its k-mers
+// labelled with each taxon: the taxon whose root-to-leaf path holds the most
+// hits, or the LCA of the tied taxa
+func ClassifyTaxon(tree *taxonomy.Taxonomy, taxonHits map[int]int) int {
+	best, bestScore := 0, 0
+	for taxon := range taxonHits {
+		score := 0
+		for id := taxon; ; id = tree.Parent(id) {
+			score += taxonHits[id]
+			if id == taxonomy.Root {
+				break
+			}
+		}
+		if score > bestScore {
+			best, bestScore = taxon, score
+		} else if score == bestScore {
+			best = tree.LCA(best, taxon)
+		}
+	}
+	return best
+}
+
+// GenomeTaxa returns the taxon of each reference: its taxid in the manifest,
+// or failing that the taxon of its assembly accession in the assembly summary
+func GenomeTaxa(references []refs.Reference, tree *taxonomy.Taxonomy, assemblies taxonomy.Assemblies) ([]int, error) {
+	taxa := make([]int, len(references))
+	for genome, reference := range references {
+		taxon := reference.TaxID
+		if taxon == 0 {
+			accession := reference.Accession
+			if accession == "" {
+				accession = taxonomy.AccessionFromPath(reference.Path)
+			}
+			if accession == "" {
+				return nil, fmt.Errorf("no taxid or assembly accession for %s", reference.Name)
+			}
+			assembly, found := assemblies.Find(accession)
+			if !found {
+				return nil, fmt.Errorf("%s of %s is not in the assembly summary", accession, reference.Name)
+			}
+			taxon = assembly.TaxID
+		}
+		if !tree.Contains(taxon) {
+			return nil, fmt.Errorf("taxon %d of %s is not in the taxonomy", taxon, reference.Name)
+		}
+		taxa[genome] = taxon
+	}
+	return taxa, nil
+}
+
+// LabelKmers returns the taxon of every k-mer in the index, by row: the LCA of
+// the genomes containing the k-mer
+func LabelKmers(kmerIndex *Index, tree *taxonomy.Taxonomy, genomeTaxa []int) ([]int32, error) {
+	numGenomes := len(kmerIndex.genomes)
+	if numGenomes > 64 {
+		return nil, fmt.Errorf("LCA labelling supports at most 64 genomes, got %d", numGenomes)
+	}
+
+	// k-mers found in the same set of genomes share a label
+	setTaxa := make(map[uint64]int32)
+	labels := make([]int32, kmerIndex.Len())
+	for row := range labels {
+		var set uint64
+		for genome := 0; genome < numGenomes; genome++ {
+			if kmerIndex.count(int32(row), genome) > 0 {
+				set |= 1 << genome
+			}
+		}
+		label, exists := setTaxa[set]
+		if !exists {
+			taxon := 0
+			for genome := range genomeTaxa {
+				if set&(1<<genome) == 0 {
+					continue
+				}
+				if taxon == 0 {
+					taxon = genomeTaxa[genome]
+				} else {
+					taxon = tree.LCA(taxon, genomeTaxa[genome])
+				}
+			}
+			label = int32(taxon)
+			setTaxa[set] = label
+		}
+		labels[row] = label
+	}
+	return labels, nil
+}
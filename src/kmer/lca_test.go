@@ -0,0 +1,109 @@
+package kmer
+
+import (
+	"io"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/Asaad47/BioAlgos-Assignment1/src/internal/testutil"
+	"github.com/Asaad47/BioAlgos-Assignment1/src/iupac"
+	"github.com/Asaad47/BioAlgos-Assignment1/src/taxonomy"
+)
+
+// taxa of the built-in taxonomy used by the tests
+const (
+	ecoliK12           = 511145 // strain Escherichia coli K-12 MG1655
+	ecoli              = 562    // species Escherichia coli
+	escherichia        = 561    // genus
+	enterobacteriaceae = 543    // family Enterobacteriaceae
+	gamma              = 1236   // class Gammaproteobacteria
+	paeruginosa        = 208964 // strain Pseudomonas aeruginosa PAO1
+	saureus            = 93061  // strain Staphylococcus aureus NCTC 8325
+)
+
+func TestClassifyTaxon(t *testing.T) {
+	tree := taxonomy.Builtin()
+	tests := []struct {
+		name string
+		hits map[int]int
+		want int
+	}{
+		{"no hits", map[int]int{}, 0},
+		{"single taxon", map[int]int{ecoli: 5}, ecoli},
+		// 511145 scores 2+3+1 along its path, 208964 scores 4+1
+		{"deepest path", map[int]int{ecoliK12: 2, ecoli: 3, gamma: 1, paeruginosa: 4}, ecoliK12},
+		{"ancestor hits count for the leaf", map[int]int{ecoliK12: 1, ecoli: 3, paeruginosa: 3}, ecoliK12},
+		{"more hits on another branch", map[int]int{ecoliK12: 1, ecoli: 1, paeruginosa: 3}, paeruginosa},
+		{"tie", map[int]int{ecoliK12: 3, paeruginosa: 3}, gamma},
+		{"tie across phyla", map[int]int{ecoliK12: 2, paeruginosa: 2, saureus: 2}, 2},
+		{"tie counting ancestor hits", map[int]int{ecoli: 2, escherichia: 2, paeruginosa: 4}, gamma},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := ClassifyTaxon(tree, test.hits); got != test.want {
+				t.Errorf("ClassifyTaxon(%v) = %d (%s), want %d (%s)", test.hits, got, tree.Name(got), test.want, tree.Name(test.want))
+			}
+		})
+	}
+}
+
+// TestLabelKmers indexes genomes assigned to a strain, a genus, a family and
+// another order and checks that the k-mers shared by some of them are labelled
+// with the LCA of their taxa
+func TestLabelKmers(t *testing.T) {
+	random := rand.New(rand.NewPCG(1, 2))
+	segment := func() string { return testutil.RandomSequence(random, 500) }
+	withGenus, withFamily, withClass, inAll := segment(), segment(), segment(), segment()
+	strainOnly := segment()
+	genomes := []string{
+		strainOnly + withGenus + withFamily + withClass + inAll,
+		segment() + withGenus + inAll,
+		segment() + withFamily + inAll,
+		segment() + withClass + inAll,
+	}
+	genomeTaxa := []int{ecoliK12, escherichia, enterobacteriaceae, paeruginosa}
+
+	tree := taxonomy.Builtin()
+	kmerIndex, _, err := BuildIndex(writeReferences(t, genomes...), 21, false, iupac.Break, iupac.DefaultMaxExpansions, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	labels, err := LabelKmers(kmerIndex, tree, genomeTaxa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(labels) != kmerIndex.Len() {
+		t.Fatalf("labelled %d k-mers, index has %d", len(labels), kmerIndex.Len())
+	}
+
+	tests := []struct {
+		name     string
+		sequence string
+		want     int
+	}{
+		{"one genome", strainOnly, ecoliK12},
+		{"strain and genus", withGenus, escherichia},
+		{"strain and family", withFamily, enterobacteriaceae},
+		{"strain and other order", withClass, gamma},
+		{"all genomes", inAll, gamma},
+	}
+	for _, test := range tests {
+		filter := &iupac.Filter{Policy: iupac.Break}
+		for _, kmer := range extractKmers(test.sequence, 21, false, filter) {
+			row, exists := kmerIndex.row(kmer)
+			if !exists {
+				t.Fatalf("%s: k-mer missing from the index", test.name)
+			}
+			if labels[row] != int32(test.want) {
+				t.Errorf("%s: k-mer labelled %d (%s), want %d (%s)", test.name, labels[row], tree.Name(int(labels[row])), test.want, tree.Name(test.want))
+				break
+			}
+		}
+
+		// a read from the region is assigned to the label of its k-mers
+		match := MatchRead("read", []byte(test.sequence[100:250]), kmerIndex, filter, labels)
+		if taxon := ClassifyTaxon(tree, match.TaxonHits); taxon != test.want {
+			t.Errorf("%s: read assigned to %d (%s), want %d (%s)", test.name, taxon, tree.Name(taxon), test.want, tree.Name(test.want))
+		}
+	}
+}
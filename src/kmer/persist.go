@@ -0,0 +1,228 @@
+package kmer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"slices"
+	"unsafe"
+
+	"github.com/Asaad47/BioAlgos-Assignment1/src/internal/binfile"
+)
+
+const (
+	indexMagic      = "BIOKMIDX"
+	indexVersion    = 1
+	indexHeaderSize = 64
+	alphabetSize    = 4 // a, c, g and t, at 2 bits per base
+)
+
+// indexHeader is the fixed-size header of a saved index. It is followed by the
+// genome names, each prefixed by its uint32 length and the whole padded to a
+// multiple of 8 bytes, then by the little-endian k-mers in increasing order
+// (one uint64 each for k <= MaxSmallK, two words hi and lo otherwise), then by
+// one column of uint32 counts per genome, in the order of the k-mers.
+type indexHeader struct {
+	Magic        [8]byte
+	Version      uint32
+	AlphabetSize uint32
+	K            uint32
+	Canonical    uint32 // 1 if the index holds canonical k-mers
+	NumGenomes   uint32
+	_            uint32
+	NumKmers     uint64
+	GenomesSize  uint64 // size of the padded genome names
+	Checksum     uint32 // CRC-32C of everything after the header
+	_            [12]byte
+}
+
+// Save writes the index to path so that Load can reuse it without rebuilding.
+// The file is replaced only once it is completely written, so processes that
+// mapped the previous file keep reading it.
+func (idx *Index) Save(path string) error {
+	numGenomes := len(idx.genomes)
+
+	var genomeTable bytes.Buffer
+	for _, genome := range idx.genomes {
+		binary.Write(&genomeTable, binary.LittleEndian, uint32(len(genome)))
+		genomeTable.WriteString(genome)
+	}
+	for genomeTable.Len()%8 != 0 {
+		genomeTable.WriteByte(0)
+	}
+
+	// rows in increasing order of their k-mers, the keys being written as
+	// one word per k-mer or as hi and lo words for k > MaxSmallK
+	var keys []uint64
+	var wide []Kmer
+	rows := make([]int32, 0, idx.Len())
+	switch {
+	case idx.columns != nil:
+		keys, wide = idx.sortedKmers, idx.sortedWide
+		for row := 0; row < idx.Len(); row++ {
+			rows = append(rows, int32(row))
+		}
+	case idx.wide != nil:
+		for kmer := range idx.wide {
+			wide = append(wide, kmer)
+		}
+		slices.SortFunc(wide, compareKmers)
+		for _, kmer := range wide {
+			rows = append(rows, idx.wide[kmer])
+		}
+	default:
+		for kmer := range idx.kmers {
+			keys = append(keys, kmer)
+		}
+		slices.Sort(keys)
+		for _, kmer := range keys {
+			rows = append(rows, idx.kmers[kmer])
+		}
+	}
+	if idx.k > MaxSmallK {
+		keys = make([]uint64, 0, 2*len(wide))
+		for _, kmer := range wide {
+			keys = append(keys, kmer.hi, kmer.lo)
+		}
+	}
+
+	header := indexHeader{
+		Version:      indexVersion,
+		AlphabetSize: alphabetSize,
+		K:            uint32(idx.k),
+		NumGenomes:   uint32(numGenomes),
+		NumKmers:     uint64(len(rows)),
+		GenomesSize:  uint64(genomeTable.Len()),
+	}
+	if idx.canonical {
+		header.Canonical = 1
+	}
+	copy(header.Magic[:], indexMagic)
+
+	file, err := binfile.Create(path, indexHeaderSize)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if _, err := file.Write(genomeTable.Bytes()); err != nil {
+		return err
+	}
+	if err := binary.Write(file, binary.LittleEndian, keys); err != nil {
+		return err
+	}
+	column := make([]uint32, len(rows))
+	for genome := 0; genome < numGenomes; genome++ {
+		for i, row := range rows {
+			column[i] = idx.count(row, genome)
+		}
+		if err := binary.Write(file, binary.LittleEndian, column); err != nil {
+			return err
+		}
+	}
+	if header.Checksum, err = file.Checksum(); err != nil {
+		return err
+	}
+	return file.Commit(&header)
+}
+
+// Load memory-maps an index written by Save. The k-mers and counts are used in
+// place, so the index is read-only and should be released with Close.
+func Load(path string) (*Index, error) {
+	data, err := binfile.Map(path)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := decodeIndex(data)
+	if err != nil {
+		binfile.Unmap(data)
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return idx, nil
+}
+
+// decodeIndex validates the header and checksum of a saved index and builds
+// an Index whose k-mers and counts point into data
+func decodeIndex(data []byte) (*Index, error) {
+	if len(data) < indexHeaderSize {
+		return nil, fmt.Errorf("file too short for a k-mer index")
+	}
+	var header indexHeader
+	if err := binary.Read(bytes.NewReader(data[:indexHeaderSize]), binary.LittleEndian, &header); err != nil {
+		return nil, err
+	}
+	if string(header.Magic[:]) != indexMagic {
+		return nil, fmt.Errorf("not a k-mer index file")
+	}
+	if header.Version != indexVersion {
+		return nil, fmt.Errorf("unsupported k-mer index version %d (expected %d)", header.Version, indexVersion)
+	}
+	if header.AlphabetSize != alphabetSize {
+		return nil, fmt.Errorf("alphabet size %d does not match %d", header.AlphabetSize, alphabetSize)
+	}
+	k := int(header.K)
+	if k < 1 || k > MaxK {
+		return nil, fmt.Errorf("k = %d is not between 1 and %d", k, MaxK)
+	}
+
+	numKmers := int(header.NumKmers)
+	numGenomes := int(header.NumGenomes)
+	keySize := 8
+	if k > MaxSmallK {
+		keySize = 16
+	}
+	expectedSize := indexHeaderSize + int(header.GenomesSize) + numKmers*(keySize+4*numGenomes)
+	if len(data) != expectedSize || header.GenomesSize%8 != 0 {
+		return nil, fmt.Errorf("file size %d does not match header (%d)", len(data), expectedSize)
+	}
+	if crc32.Checksum(data[indexHeaderSize:], binfile.Castagnoli) != header.Checksum {
+		return nil, fmt.Errorf("checksum mismatch, file is corrupted")
+	}
+
+	idx := &Index{k: k, canonical: header.Canonical == 1, mapped: data}
+	offset := indexHeaderSize
+	genomesEnd := offset + int(header.GenomesSize)
+	for len(idx.genomes) < numGenomes {
+		if offset+4 > genomesEnd {
+			return nil, fmt.Errorf("truncated genome names")
+		}
+		length := int(binary.LittleEndian.Uint32(data[offset:]))
+		offset += 4
+		if offset+length > genomesEnd {
+			return nil, fmt.Errorf("truncated genome names")
+		}
+		idx.genomes = append(idx.genomes, string(data[offset:offset+length]))
+		offset += length
+	}
+	offset = genomesEnd
+
+	if k > MaxSmallK {
+		words := binfile.MappedSlice[uint64](data, &offset, 2*numKmers)
+		if binfile.HostLittleEndian && numKmers > 0 {
+			// a Kmer is laid out as its hi and lo words
+			idx.sortedWide = unsafe.Slice((*Kmer)(unsafe.Pointer(&words[0])), numKmers)
+		} else {
+			idx.sortedWide = make([]Kmer, numKmers)
+			for i := range idx.sortedWide {
+				idx.sortedWide[i] = Kmer{hi: words[2*i], lo: words[2*i+1]}
+			}
+		}
+	} else {
+		idx.sortedKmers = binfile.MappedSlice[uint64](data, &offset, numKmers)
+	}
+	idx.columns = make([][]uint32, numGenomes)
+	for genome := range idx.columns {
+		idx.columns[genome] = binfile.MappedSlice[uint32](data, &offset, numKmers)
+	}
+	return idx, nil
+}
+
+// Close releases the memory mapping of an index loaded by Load
+func (idx *Index) Close() error {
+	if idx.mapped == nil {
+		return nil
+	}
+	err := binfile.Unmap(idx.mapped)
+	idx.mapped = nil
+	return err
+}
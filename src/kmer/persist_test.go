@@ -0,0 +1,93 @@
+package kmer
+
+import (
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/Asaad47/BioAlgos-Assignment1/src/internal/testutil"
+	"github.com/Asaad47/BioAlgos-Assignment1/src/iupac"
+)
+
+// TestSaveLoad saves an index and checks that the loaded index counts every
+// k-mer of the built one in the same genomes
+func TestSaveLoad(t *testing.T) {
+	random := rand.New(rand.NewPCG(1, 2))
+	shared := testutil.RandomSequence(random, 5000)
+	references := writeReferences(t,
+		testutil.RandomSequence(random, 20000)+shared,
+		shared+testutil.RandomSequence(random, 20000),
+		testutil.RandomSequence(random, 10000))
+	for _, k := range []int{21, 32, 33, 64} {
+		for _, canonical := range []bool{false, true} {
+			t.Run(fmt.Sprintf("k=%d/canonical=%v", k, canonical), func(t *testing.T) {
+				built, _, err := BuildIndex(references, k, canonical, iupac.Break, iupac.DefaultMaxExpansions, io.Discard)
+				if err != nil {
+					t.Fatal(err)
+				}
+				path := filepath.Join(t.TempDir(), "index.kmi")
+				if err := built.Save(path); err != nil {
+					t.Fatal(err)
+				}
+				loaded, err := Load(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer loaded.Close()
+
+				if loaded.K() != k || loaded.Canonical() != canonical || !slices.Equal(loaded.Genomes(), built.Genomes()) || loaded.Len() != built.Len() {
+					t.Fatalf("loaded k=%d canonical=%v genomes %v with %d k-mers, want k=%d canonical=%v genomes %v with %d k-mers",
+						loaded.K(), loaded.Canonical(), loaded.Genomes(), loaded.Len(), k, canonical, built.Genomes(), built.Len())
+				}
+				check := func(kmer Kmer, builtRow int32) {
+					row, exists := loaded.row(kmer)
+					if !exists {
+						t.Fatalf("k-mer %x %x missing from the loaded index", kmer.hi, kmer.lo)
+					}
+					for genome := range built.Genomes() {
+						if got, want := loaded.count(row, genome), built.count(builtRow, genome); got != want {
+							t.Fatalf("k-mer %x %x counted %d times in genome %d, want %d", kmer.hi, kmer.lo, got, genome, want)
+						}
+					}
+				}
+				for kmer, row := range built.kmers {
+					check(Kmer{lo: kmer}, row)
+				}
+				for kmer, row := range built.wide {
+					check(kmer, row)
+				}
+			})
+		}
+	}
+}
+
+// TestLoadTruncated checks that a cut-off index file is rejected
+func TestLoadTruncated(t *testing.T) {
+	random := rand.New(rand.NewPCG(1, 2))
+	references := writeReferences(t, testutil.RandomSequence(random, 10000))
+	built, _, err := BuildIndex(references, 31, false, iupac.Break, iupac.DefaultMaxExpansions, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "index.kmi")
+	if err := built.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, size := range []int{0, 10, len(data) / 2, len(data) - 1} {
+		if err := os.WriteFile(path, data[:size], 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if loaded, err := Load(path); err == nil {
+			loaded.Close()
+			t.Errorf("loaded an index cut to %d of %d bytes", size, len(data))
+		}
+	}
+}
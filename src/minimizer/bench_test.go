@@ -0,0 +1,104 @@
+package minimizer
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"testing"
+
+	"github.com/Asaad47/BioAlgos-Assignment1/src/internal/testutil"
+	"github.com/Asaad47/BioAlgos-Assignment1/src/iupac"
+)
+
+// getMinimizer selects the representative k-mer from a window, the
+// lexicographically smallest k-mer, as the original index and classifier did
+func getMinimizer(kmers []string) string {
+	sort.Strings(kmers) // Lexicographic order
+	return kmers[0]     // choose the smallest k-mer as minimizer
+}
+
+// windowKmers returns the k-mers of a window of w consecutive k-mers that are
+// left after applying the ambiguity policy
+func windowKmers(window string, k, w int, filter *Filter) []string {
+	kmers := make([]string, 0, w)
+	for j := 0; j < w; j++ {
+		filter.Resolve(window[j:j+k], func(kmer string) {
+			kmers = append(kmers, kmer)
+		})
+	}
+	if len(kmers) == 0 {
+		filter.SkippedWindows++
+	}
+	return kmers
+}
+
+// legacyWindowMinimizers is the original minimizer selection, sorting the
+// k-mer strings of each window, kept only as the baseline of windowMinimizers
+func legacyWindowMinimizers(sequence string, k, w int, filter *Filter, fn func(minimizer string)) {
+	for i := 0; i <= len(sequence)-w-k+1; i++ {
+		kmers := windowKmers(sequence[i:i+w+k-1], k, w, filter)
+		if len(kmers) == 0 {
+			continue
+		}
+		fn(getMinimizer(kmers))
+	}
+}
+
+// randomSequence returns n random lowercase bases; with ambiguous set, about
+// one base in a hundred is an ambiguity code instead and a poly-A run is added
+func randomSequence(random *rand.Rand, n int, ambiguous bool) string {
+	sequence := make([]byte, n)
+	for i := range sequence {
+		sequence[i] = "acgt"[random.IntN(4)]
+		if ambiguous && random.IntN(100) == 0 {
+			sequence[i] = "nrykmswbdhv"[random.IntN(11)]
+		}
+	}
+	if ambiguous && n >= 200 {
+		for i := n / 2; i < n/2+100; i++ {
+			sequence[i] = 'a'
+		}
+	}
+	return string(sequence)
+}
+
+// BenchmarkWindowMinimizers compares the monotone deque with sorting the k-mer
+// strings of every window, on a random 1 Mbp sequence, or on the E. coli
+// genome, the first reference genome, with -reference-genomes
+func BenchmarkWindowMinimizers(b *testing.B) {
+	sequences := []string{randomSequence(rand.New(rand.NewPCG(1, 2)), 1<<20, false)}
+	if testutil.UseReferenceGenomes() {
+		var err error
+		if sequences, err = readGenome(testutil.ReferenceGenomes(b)[0].Path); err != nil {
+			b.Fatal(err)
+		}
+	}
+	var size int64
+	for _, sequence := range sequences {
+		size += int64(len(sequence))
+	}
+	newFilter := func() *Filter {
+		return &Filter{Filter: iupac.Filter{Policy: iupac.Break, MaxExpansions: iupac.DefaultMaxExpansions}}
+	}
+	for _, k := range []int{15, 31} {
+		b.Run(fmt.Sprintf("sort-per-window/k=%d", k), func(b *testing.B) {
+			b.SetBytes(size)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				for _, sequence := range sequences {
+					legacyWindowMinimizers(sequence, k, 10, newFilter(), func(string) {})
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("deque/k=%d", k), func(b *testing.B) {
+			order := &Order{Ordering: Lexicographic}
+			b.SetBytes(size)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				for _, sequence := range sequences {
+					windowMinimizers(sequence, k, 10, order, newFilter(), func(int, uint64) {})
+				}
+			}
+		})
+	}
+}
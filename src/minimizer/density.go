@@ -0,0 +1,87 @@
+package minimizer
+
+import (
+	"io"
+	"math/rand/v2"
+
+	"github.com/Asaad47/BioAlgos-Assignment1/src/iupac"
+	"github.com/Asaad47/BioAlgos-Assignment1/src/refs"
+)
+
+// Density counts the k-mers of a sequence selected as minimizers
+type Density struct {
+	Kmers    int // number of k-mer positions
+	Selected int // number of distinct positions selected by some window
+}
+
+// Value returns the fraction of k-mers selected as minimizers
+func (d Density) Value() float64 {
+	if d.Kmers == 0 {
+		return 0
+	}
+	return float64(d.Selected) / float64(d.Kmers)
+}
+
+// ExpectedDensity returns 2/(w+1), the density of minimizers under a random
+// order of the k-mers
+func ExpectedDensity(w int) float64 {
+	return 2 / float64(w+1)
+}
+
+// sequenceDensity returns the density of the minimizers of a sequence under an order
+func sequenceDensity(sequence string, k, w int, order *Order, filter *Filter) Density {
+	density := Density{Kmers: max(len(sequence)-k+1, 0)}
+	last := -1
+	windowMinimizers(sequence, k, w, order, filter, func(position int, minimizer uint64) {
+		if position != last {
+			density.Selected++
+			last = position
+		}
+	})
+	return density
+}
+
+// GenomeDensity holds the density of the minimizers of a genome under each
+// order compared by MeasureDensities
+type GenomeDensity struct {
+	Genome    string
+	Densities []Density // in the order of the compared orders
+}
+
+// MeasureDensities measures, on each reference genome, the density of the
+// minimizers selected under every ordering, and returns the orders compared
+// with the densities. The Random order uses seed, or a random seed if it is 0,
+// and the Frequency order ranks the given fraction of the most frequent k-mers
+// of all the references last.
+func MeasureDensities(references []refs.Reference, k, w int, seed uint64, fraction float64, policy iupac.Policy, maxExpansions int) ([]*Order, []GenomeDensity, error) {
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	orders := []*Order{
+		{Ordering: Lexicographic},
+		{Ordering: Hash},
+		{Ordering: Random, Seed: seed},
+		{Ordering: Frequency, Fraction: fraction},
+	}
+	if err := orders[3].CountFrequent(references, k, io.Discard); err != nil {
+		return nil, nil, err
+	}
+
+	genomes := make([]GenomeDensity, len(references))
+	for g, reference := range references {
+		sequences, err := readGenome(reference.Path)
+		if err != nil {
+			return nil, nil, err
+		}
+		genomes[g] = GenomeDensity{Genome: reference.Name, Densities: make([]Density, len(orders))}
+		for o, order := range orders {
+			total := &genomes[g].Densities[o]
+			for _, sequence := range sequences {
+				density := sequenceDensity(sequence, k, w, order, &Filter{Filter: iupac.Filter{Policy: policy, MaxExpansions: maxExpansions}})
+				total.Kmers += density.Kmers
+				total.Selected += density.Selected
+			}
+		}
+	}
+	return orders, genomes, nil
+}
@@ -0,0 +1,78 @@
+package minimizer
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/Asaad47/BioAlgos-Assignment1/src/internal/testutil"
+	"github.com/Asaad47/BioAlgos-Assignment1/src/iupac"
+	"github.com/Asaad47/BioAlgos-Assignment1/src/refs"
+)
+
+func TestSequenceDensity(t *testing.T) {
+	tests := []struct {
+		sequence string
+		k, w     int
+		want     Density
+	}{
+		// a, c and g each lead one window of two
+		{"acgt", 1, 2, Density{Kmers: 4, Selected: 3}},
+		// the a at position 2 is the minimizer of every window
+		{"gcatg", 1, 3, Density{Kmers: 5, Selected: 1}},
+		// ac leads the first two windows, ag the last
+		{"tacag", 2, 2, Density{Kmers: 4, Selected: 2}},
+		// ties go to the leftmost k-mer, so each window selects its first
+		{"aaaaaaa", 2, 3, Density{Kmers: 6, Selected: 4}},
+		// every k-mer is selected with windows of one k-mer
+		{"gattaca", 3, 1, Density{Kmers: 5, Selected: 5}},
+		{"ac", 3, 1, Density{}},
+	}
+	lexicographic := &Order{Ordering: Lexicographic}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s/k=%d/w=%d", test.sequence, test.k, test.w), func(t *testing.T) {
+			filter := &Filter{Filter: iupac.Filter{Policy: iupac.Break}}
+			if got := sequenceDensity(test.sequence, test.k, test.w, lexicographic, filter); got != test.want {
+				t.Errorf("density is %+v, want %+v", got, test.want)
+			}
+		})
+	}
+	if value := (Density{Kmers: 4, Selected: 3}).Value(); value != 0.75 {
+		t.Errorf("Value() = %v, want 0.75", value)
+	}
+	if value := (Density{}).Value(); value != 0 {
+		t.Errorf("Value() of no k-mers = %v, want 0", value)
+	}
+}
+
+// TestMeasureDensities checks that every order is measured on every genome and
+// that random orders select about 2/(w+1) of the k-mers of a random genome
+func TestMeasureDensities(t *testing.T) {
+	random := rand.New(rand.NewPCG(1, 2))
+	references := []refs.Reference{
+		{Name: "one", Path: testutil.WriteFasta(t, "one.fna", testutil.RandomSequence(random, 50000))},
+		{Name: "two", Path: testutil.WriteFasta(t, "two.fna", testutil.RandomSequence(random, 20000), testutil.RandomSequence(random, 10))},
+	}
+	const k, w = 15, 10
+	orders, genomes, err := MeasureDensities(references, k, w, 0, DefaultFrequentFraction, iupac.Break, iupac.DefaultMaxExpansions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orders) != 4 || orders[2].Ordering != Random || orders[2].Seed == 0 {
+		t.Fatalf("compared orders %v, want the four orderings and a random seed", orders)
+	}
+	wantKmers := []int{50000 - k + 1, 20000 - k + 1}
+	for g, genome := range genomes {
+		if genome.Genome != references[g].Name || len(genome.Densities) != len(orders) {
+			t.Fatalf("genome %d measured as %s with %d densities", g, genome.Genome, len(genome.Densities))
+		}
+		for o, density := range genome.Densities {
+			if density.Kmers != wantKmers[g] {
+				t.Errorf("%s under %s: %d k-mers, want %d", genome.Genome, orders[o], density.Kmers, wantKmers[g])
+			}
+			if ratio := density.Value() / ExpectedDensity(w); ratio < 0.8 || ratio > 1.2 {
+				t.Errorf("%s under %s: density %.4f is far from 2/(w+1) = %.4f", genome.Genome, orders[o], density.Value(), ExpectedDensity(w))
+			}
+		}
+	}
+}
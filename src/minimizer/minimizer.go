@@ -0,0 +1,206 @@
+// Package minimizer indexes only the minimizers of reference genomes, the
+// k-mer of lowest rank under an Order in every window of w consecutive k-mers,
+// and classifies reads by the genomes sharing their minimizers.
+package minimizer
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/Asaad47/BioAlgos-Assignment1/src/iupac"
+	"github.com/Asaad47/BioAlgos-Assignment1/src/refs"
+	"github.com/Asaad47/BioAlgos-Assignment1/src/seqio"
+)
+
+// Index stores selected k-mers as minimizers
+// mapped to their occurrences in reference genomes
+type Index struct {
+	minimizers map[uint64]map[string]int // packed minimizer -> genome -> count
+	genomes    []string                  // genomes in the order they were added
+	k          int
+	w          int
+	order      Order // ranking of the k-mers of a window
+}
+
+// NewIndex returns an empty index of the minimizers of windows of w k-mers,
+// where k is at most MaxK, under the given order. A Frequency order must have
+// counted its frequent k-mers.
+func NewIndex(k, w int, order Order) *Index {
+	return &Index{minimizers: make(map[uint64]map[string]int), k: k, w: w, order: order}
+}
+
+// K returns the k-mer length of the index
+func (index *Index) K() int {
+	return index.k
+}
+
+// W returns the number of consecutive k-mers in each window of the index
+func (index *Index) W() int {
+	return index.w
+}
+
+// Order returns the order ranking the k-mers of each window
+func (index *Index) Order() *Order {
+	return &index.order
+}
+
+// Genomes returns the names of the indexed genomes in the order they were added
+func (index *Index) Genomes() []string {
+	return index.genomes
+}
+
+// Len returns the number of distinct minimizers in the index
+func (index *Index) Len() int {
+	return len(index.minimizers)
+}
+
+// Filter applies an ambiguity policy to the k-mers of each window and counts
+// the windows it left without any k-mer
+type Filter struct {
+	iupac.Filter
+	SkippedWindows int // number of windows left without any k-mer
+}
+
+// ReadMatch tracks matches for a single sequence read
+type ReadMatch struct {
+	ReadID       string
+	MatchedOrgs  map[string]int // maps organism to number of minimizer matches
+	TotalMatches int            // total number of minimizer matches across all organisms
+}
+
+// BuildIndex creates an index storing only minimizers, writing progress to
+// the given writer. The frequent k-mers of a Frequency order are counted in the
+// references unless they already are.
+func BuildIndex(references []refs.Reference, k, w int, order Order, policy iupac.Policy, maxExpansions int, progress io.Writer) (*Index, error) {
+	if order.Ordering == Frequency && order.frequent == nil {
+		if err := order.CountFrequent(references, k, progress); err != nil {
+			return nil, err
+		}
+	}
+	minimizerIndex := NewIndex(k, w, order)
+	for _, reference := range references {
+		if err := minimizerIndex.AddGenome(reference, policy, maxExpansions, progress); err != nil {
+			return nil, err
+		}
+	}
+	return minimizerIndex, nil
+}
+
+// AddGenome adds the minimizers of a reference genome to the index, leaving
+// those of the genomes already indexed untouched
+func (index *Index) AddGenome(reference refs.Reference, policy iupac.Policy, maxExpansions int, progress io.Writer) error {
+	genomeName := reference.Name
+	if slices.Contains(index.genomes, genomeName) {
+		return fmt.Errorf("genome %s is already in the index", genomeName)
+	}
+	file, err := seqio.Open(reference.Path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	fmt.Fprintf(progress, "Processing genome: %s\n", genomeName)
+	genomeLength := 0
+	ambiguous := 0
+	filter := Filter{Filter: iupac.Filter{Policy: policy, MaxExpansions: maxExpansions}}
+	var density Density
+
+	reader := seqio.NewFastaReader(file)
+	for {
+		record, err := reader.ReadRecord()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", reference.Path, err)
+		}
+		sequence := strings.ToLower(string(record.Sequence))
+		genomeLength += len(sequence)
+		ambiguous += iupac.CountAmbiguous(record.Sequence)
+		density.Kmers += max(len(sequence)-index.k+1, 0)
+		last := -1
+		windowMinimizers(sequence, index.k, index.w, &index.order, &filter, func(position int, minimizer uint64) {
+			if _, exists := index.minimizers[minimizer]; !exists {
+				index.minimizers[minimizer] = make(map[string]int)
+			}
+			index.minimizers[minimizer][genomeName]++
+			// consecutive windows often share their minimizer
+			if position != last {
+				density.Selected++
+				last = position
+			}
+		})
+	}
+	index.genomes = append(index.genomes, genomeName)
+	fmt.Fprintf(progress, "Genome length (%s): %d\n", genomeName, genomeLength)
+	fmt.Fprintf(progress, "Ambiguity codes (%s): %d bases, %d windows skipped\n", genomeName, ambiguous, filter.SkippedWindows)
+	fmt.Fprintf(progress, "Minimizer density (%s): %.4f (%d of %d k-mers), %.2fx the 2/(w+1) = %.4f of a random order\n",
+		genomeName, density.Value(), density.Selected, density.Kmers, density.Value()/ExpectedDensity(index.w), ExpectedDensity(index.w))
+	return nil
+}
+
+// ClassifyReads classifies reads using the minimizer index, writing the number
+// of malformed records skipped with skipMalformed to progress
+func ClassifyReads(readFiles []string, index *Index, filter *Filter, skipMalformed bool, progress io.Writer) (map[string]*ReadMatch, error) {
+	readMatches := make(map[string]*ReadMatch)
+
+	for _, readFile := range readFiles {
+		file, err := seqio.Open(readFile)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		reader := seqio.NewFastqReader(file)
+		reader.SkipMalformed = skipMalformed
+		for {
+			record, err := reader.ReadRecord()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", readFile, err)
+			}
+			readID := record.Header() + "_" + readFile
+			readMatches[readID] = &ReadMatch{
+				ReadID:      readID,
+				MatchedOrgs: make(map[string]int),
+			}
+			sequence := strings.ToLower(string(record.Sequence))
+			windowMinimizers(sequence, index.k, index.w, &index.order, filter, func(position int, minimizer uint64) {
+				for genome, count := range index.minimizers[minimizer] {
+					readMatches[readID].MatchedOrgs[genome] += count
+					readMatches[readID].TotalMatches += count
+				}
+			})
+		}
+		if reader.Skipped() > 0 {
+			fmt.Fprintf(progress, "Skipped %d malformed reads in %s (first at %v)\n", reader.Skipped(), readFile, reader.FirstSkipped())
+		}
+	}
+	return readMatches, nil
+}
+
+// readGenome returns the lowercase sequence of every record of a FASTA file
+func readGenome(path string) ([]string, error) {
+	file, err := seqio.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var sequences []string
+	reader := seqio.NewFastaReader(file)
+	for {
+		record, err := reader.ReadRecord()
+		if err == io.EOF {
+			return sequences, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		sequences = append(sequences, strings.ToLower(string(record.Sequence)))
+	}
+}
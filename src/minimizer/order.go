@@ -0,0 +1,136 @@
+package minimizer
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+
+	"github.com/Asaad47/BioAlgos-Assignment1/src/refs"
+)
+
+// Ordering decides how the k-mers of a window are ranked; the minimizer is
+// the k-mer of lowest rank
+type Ordering int
+
+const (
+	Lexicographic Ordering = iota // k-mers ranked as strings, which favours poly-A runs and low-complexity sequence
+	Hash                          // k-mers ranked by minimap2's invertible integer hash
+	Random                        // k-mers xored with a random seed, then hashed
+	Frequency                     // k-mers ranked by hash, frequent k-mers of the genomes after all others
+)
+
+// DefaultFrequentFraction is the fraction of the most frequent k-mers that
+// Frequency ranks last, as minimap2 ignores the top 0.02% of its minimizers
+const DefaultFrequentFraction = 0.0002
+
+var orderingNames = []string{"lexicographic", "hash", "random", "frequency"}
+
+func (o Ordering) String() string {
+	return orderingNames[o]
+}
+
+func ParseOrdering(name string) (Ordering, error) {
+	for i, orderingName := range orderingNames {
+		if name == orderingName {
+			return Ordering(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown minimizer ordering %q (expected lexicographic, hash, random or frequency)", name)
+}
+
+// Order ranks packed k-mers under an Ordering
+type Order struct {
+	Ordering Ordering
+	Seed     uint64  // xored into each k-mer before hashing, for Random
+	Fraction float64 // fraction of the most frequent k-mers ranked last, for Frequency
+	frequent map[uint64]struct{}
+}
+
+// hash64 is the invertible integer hash of minimap2, a bijection on the
+// k-mers kept by mask
+func hash64(key, mask uint64) uint64 {
+	key = (^key + (key << 21)) & mask
+	key = key ^ key>>24
+	key = (key + (key << 3) + (key << 8)) & mask
+	key = key ^ key>>14
+	key = (key + (key << 2) + (key << 4)) & mask
+	key = key ^ key>>28
+	key = (key + (key << 31)) & mask
+	return key
+}
+
+// rank returns the rank of a packed k-mer whose bits are kept by mask
+func (order *Order) rank(kmer, mask uint64) uint64 {
+	switch order.Ordering {
+	case Hash:
+		return hash64(kmer, mask)
+	case Random:
+		return hash64((kmer^order.Seed)&mask, mask)
+	case Frequency:
+		// the top bit ranks frequent k-mers after all others
+		rank := hash64(kmer, mask) >> 1
+		if _, frequent := order.frequent[kmer]; frequent {
+			rank |= 1 << 63
+		}
+		return rank
+	}
+	return kmer
+}
+
+// Frequent returns the number of k-mers ranked last by a Frequency order
+func (order *Order) Frequent() int {
+	return len(order.frequent)
+}
+
+// CountFrequent counts the k-mers of the reference genomes and marks the
+// Fraction of them that occur most often (and more than once) to be ranked
+// last by a Frequency order. K-mers with ambiguity codes are not counted.
+func (order *Order) CountFrequent(references []refs.Reference, k int, progress io.Writer) error {
+	counts := make(map[uint64]uint32)
+	for _, reference := range references {
+		sequences, err := readGenome(reference.Path)
+		if err != nil {
+			return err
+		}
+		for _, sequence := range sequences {
+			rollKmers(sequence, k, func(start int, kmer uint64, ambiguous bool) {
+				if !ambiguous {
+					counts[kmer]++
+				}
+			})
+		}
+	}
+
+	sorted := make([]uint32, 0, len(counts))
+	for _, count := range counts {
+		sorted = append(sorted, count)
+	}
+	slices.Sort(sorted)
+	order.frequent = make(map[uint64]struct{})
+	top := min(int(math.Ceil(order.Fraction*float64(len(sorted)))), len(sorted))
+	if top == 0 {
+		fmt.Fprintf(progress, "No frequent k-mers among %d distinct k-mers\n", len(sorted))
+		return nil
+	}
+	threshold := max(sorted[len(sorted)-top], 2)
+	for kmer, count := range counts {
+		if count >= threshold {
+			order.frequent[kmer] = struct{}{}
+		}
+	}
+	fmt.Fprintf(progress, "Ranking %d frequent k-mers (at least %d occurrences) of %d distinct k-mers last\n",
+		len(order.frequent), threshold, len(sorted))
+	return nil
+}
+
+// String describes the order, with its seed or number of frequent k-mers
+func (order *Order) String() string {
+	switch order.Ordering {
+	case Random:
+		return fmt.Sprintf("%s, seed %d", order.Ordering, order.Seed)
+	case Frequency:
+		return fmt.Sprintf("%s, %d frequent k-mers", order.Ordering, len(order.frequent))
+	}
+	return order.Ordering.String()
+}
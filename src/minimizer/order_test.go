@@ -0,0 +1,78 @@
+package minimizer
+
+import (
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	"github.com/Asaad47/BioAlgos-Assignment1/src/internal/testutil"
+	"github.com/Asaad47/BioAlgos-Assignment1/src/refs"
+)
+
+// TestHashOneToOne checks that the Hash and Random orders give every k-mer
+// its own rank within the bits of the k-mer, for every k-mer of small k
+func TestHashOneToOne(t *testing.T) {
+	for k := 1; k <= 10; k++ {
+		mask := ^uint64(0) >> (64 - 2*k)
+		for _, order := range []*Order{{Ordering: Hash}, {Ordering: Random, Seed: 0x9e3779b97f4a7c15}} {
+			t.Run(fmt.Sprintf("%s/k=%d", order, k), func(t *testing.T) {
+				seen := make([]bool, mask+1)
+				for kmer := uint64(0); kmer <= mask; kmer++ {
+					rank := order.rank(kmer, mask)
+					if rank > mask || seen[rank] {
+						t.Fatalf("k-mer %s ranked %d, already taken or outside the mask %#x", decodeKmer(kmer, k), rank, mask)
+					}
+					seen[rank] = true
+				}
+			})
+		}
+	}
+}
+
+// TestFrequencyOrder checks that the k-mers counted as frequent rank after
+// every other k-mer and that the others are not ranked against their Hash order
+func TestFrequencyOrder(t *testing.T) {
+	const k = 5
+	mask := ^uint64(0) >> (64 - 2*k)
+	random := rand.New(rand.NewPCG(1, 2))
+	repeat := "acgttgcaac"
+	genome := testutil.RandomSequence(random, 2000) + strings.Repeat(repeat, 20) + testutil.RandomSequence(random, 2000)
+	references := []refs.Reference{{Name: "genome", Path: testutil.WriteFasta(t, "genome.fna", genome)}}
+
+	order := &Order{Ordering: Frequency, Fraction: 0.01}
+	if err := order.CountFrequent(references, k, io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if order.Frequent() == 0 {
+		t.Fatal("no frequent k-mers counted")
+	}
+	for i := 0; i+k <= len(repeat); i++ {
+		kmer, _ := encodeKmer(repeat[i : i+k])
+		if _, frequent := order.frequent[kmer]; !frequent {
+			t.Errorf("repeated k-mer %s is not frequent", repeat[i:i+k])
+		}
+	}
+
+	var lastOther uint64
+	firstFrequent := ^uint64(0)
+	hash := &Order{Ordering: Hash}
+	for kmer := uint64(0); kmer <= mask; kmer++ {
+		rank := order.rank(kmer, mask)
+		if _, frequent := order.frequent[kmer]; frequent {
+			firstFrequent = min(firstFrequent, rank)
+			continue
+		}
+		lastOther = max(lastOther, rank)
+		for _, other := range []uint64{kmer ^ 1, kmer ^ 6} {
+			if _, frequent := order.frequent[other]; !frequent &&
+				hash.rank(kmer, mask) < hash.rank(other, mask) && rank > order.rank(other, mask) {
+				t.Fatalf("k-mers %s and %s are ranked unlike the Hash order", decodeKmer(kmer, k), decodeKmer(other, k))
+			}
+		}
+	}
+	if firstFrequent <= lastOther {
+		t.Errorf("a frequent k-mer ranks %d, before another k-mer ranked %d", firstFrequent, lastOther)
+	}
+}
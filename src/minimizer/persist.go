@@ -0,0 +1,211 @@
+package minimizer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"slices"
+
+	"github.com/Asaad47/BioAlgos-Assignment1/src/internal/binfile"
+)
+
+const (
+	indexMagic      = "BIOMZIDX"
+	indexVersion    = 1
+	indexHeaderSize = 80
+)
+
+// indexHeader is the fixed-size header of a saved index. It is followed by the
+// genome names, each prefixed by its uint32 length, then by the frequent k-mers
+// of a Frequency order as little-endian packed uint64s, then by the minimizers
+// in increasing order, each as its packed uint64, the uint32 number of genomes
+// it occurs in and a uint32 pair of genome number and count for each of them.
+type indexHeader struct {
+	Magic         [8]byte
+	Version       uint32
+	K             uint32
+	W             uint32
+	NumGenomes    uint32
+	Ordering      uint32
+	_             uint32
+	Seed          uint64 // seed of a Random order
+	NumFrequent   uint64 // number of frequent k-mers of a Frequency order
+	NumMinimizers uint64
+	NumEntries    uint64 // number of genome and count pairs
+	GenomesSize   uint64 // size of the genome names
+	Checksum      uint32 // CRC-32C of everything after the header
+	_             [4]byte
+}
+
+// Save writes the index to path so that Load can reuse it, and AddGenome
+// extend it under the same order, without reprocessing its genomes. The file
+// is replaced only once it is completely written.
+func (index *Index) Save(path string) error {
+	var genomeTable bytes.Buffer
+	for _, genome := range index.genomes {
+		binary.Write(&genomeTable, binary.LittleEndian, uint32(len(genome)))
+		genomeTable.WriteString(genome)
+	}
+
+	minimizers := make([]uint64, 0, len(index.minimizers))
+	numEntries := 0
+	for minimizer, genomeCounts := range index.minimizers {
+		minimizers = append(minimizers, minimizer)
+		numEntries += len(genomeCounts)
+	}
+	slices.Sort(minimizers)
+	frequent := make([]uint64, 0, len(index.order.frequent))
+	for kmer := range index.order.frequent {
+		frequent = append(frequent, kmer)
+	}
+	slices.Sort(frequent)
+
+	header := indexHeader{
+		Version:       indexVersion,
+		K:             uint32(index.k),
+		W:             uint32(index.w),
+		NumGenomes:    uint32(len(index.genomes)),
+		Ordering:      uint32(index.order.Ordering),
+		Seed:          index.order.Seed,
+		NumFrequent:   uint64(len(frequent)),
+		NumMinimizers: uint64(len(minimizers)),
+		NumEntries:    uint64(numEntries),
+		GenomesSize:   uint64(genomeTable.Len()),
+	}
+	copy(header.Magic[:], indexMagic)
+
+	file, err := binfile.Create(path, indexHeaderSize)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if _, err := file.Write(genomeTable.Bytes()); err != nil {
+		return err
+	}
+	if err := binary.Write(file, binary.LittleEndian, frequent); err != nil {
+		return err
+	}
+	entry := make([]uint32, 0, 2*len(index.genomes))
+	for _, minimizer := range minimizers {
+		binary.Write(file, binary.LittleEndian, minimizer)
+		entry = entry[:0]
+		for number, genome := range index.genomes {
+			if count, exists := index.minimizers[minimizer][genome]; exists {
+				entry = append(entry, uint32(number), uint32(count))
+			}
+		}
+		binary.Write(file, binary.LittleEndian, uint32(len(entry)/2))
+		if err := binary.Write(file, binary.LittleEndian, entry); err != nil {
+			return err
+		}
+	}
+	if header.Checksum, err = file.Checksum(); err != nil {
+		return err
+	}
+	return file.Commit(&header)
+}
+
+// Load reads an index written by Save. The index can be extended with
+// AddGenome, as it keeps the k and w it was built with.
+func Load(path string) (*Index, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	index, err := decodeIndex(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return index, nil
+}
+
+// decodeIndex validates the header and checksum of a saved index and rebuilds
+// its minimizer map
+func decodeIndex(data []byte) (*Index, error) {
+	if len(data) < indexHeaderSize {
+		return nil, fmt.Errorf("file too short for a minimizer index")
+	}
+	var header indexHeader
+	if err := binary.Read(bytes.NewReader(data[:indexHeaderSize]), binary.LittleEndian, &header); err != nil {
+		return nil, err
+	}
+	if string(header.Magic[:]) != indexMagic {
+		return nil, fmt.Errorf("not a minimizer index file")
+	}
+	if header.Version != indexVersion {
+		return nil, fmt.Errorf("unsupported minimizer index version %d (expected %d)", header.Version, indexVersion)
+	}
+	if header.Ordering >= uint32(len(orderingNames)) {
+		return nil, fmt.Errorf("unknown minimizer ordering %d", header.Ordering)
+	}
+	if header.NumFrequent > 0 && Ordering(header.Ordering) != Frequency {
+		return nil, fmt.Errorf("frequent k-mers stored for the %s ordering", Ordering(header.Ordering))
+	}
+	k, w := int(header.K), int(header.W)
+	if k < 1 || k > MaxK || w < 1 {
+		return nil, fmt.Errorf("invalid k = %d or w = %d (k must be between 1 and %d)", k, w, MaxK)
+	}
+	expectedSize := uint64(indexHeaderSize) + header.GenomesSize + header.NumFrequent*8 +
+		header.NumMinimizers*(8+4) + header.NumEntries*8
+	if uint64(len(data)) != expectedSize {
+		return nil, fmt.Errorf("file size %d does not match header (%d)", len(data), expectedSize)
+	}
+	if crc32.Checksum(data[indexHeaderSize:], binfile.Castagnoli) != header.Checksum {
+		return nil, fmt.Errorf("checksum mismatch, file is corrupted")
+	}
+
+	order := Order{Ordering: Ordering(header.Ordering), Seed: header.Seed}
+	if order.Ordering == Frequency {
+		order.frequent = make(map[uint64]struct{}, header.NumFrequent)
+	}
+	index := NewIndex(k, w, order)
+	offset := indexHeaderSize
+	genomesEnd := offset + int(header.GenomesSize)
+	for len(index.genomes) < int(header.NumGenomes) {
+		if offset+4 > genomesEnd {
+			return nil, fmt.Errorf("truncated genome names")
+		}
+		length := int(binary.LittleEndian.Uint32(data[offset:]))
+		offset += 4
+		if offset+length > genomesEnd {
+			return nil, fmt.Errorf("truncated genome names")
+		}
+		index.genomes = append(index.genomes, string(data[offset:offset+length]))
+		offset += length
+	}
+	offset = genomesEnd
+
+	for range header.NumFrequent {
+		index.order.frequent[binary.LittleEndian.Uint64(data[offset:])] = struct{}{}
+		offset += 8
+	}
+
+	mask := ^uint64(0) >> (64 - 2*k)
+	for range header.NumMinimizers {
+		if offset+8+4 > len(data) {
+			return nil, fmt.Errorf("truncated minimizers")
+		}
+		minimizer := binary.LittleEndian.Uint64(data[offset:])
+		if minimizer&^mask != 0 {
+			return nil, fmt.Errorf("minimizer %#x has more than %d bases", minimizer, k)
+		}
+		numGenomes := int(binary.LittleEndian.Uint32(data[offset+8:]))
+		offset += 8 + 4
+		if offset+8*numGenomes > len(data) {
+			return nil, fmt.Errorf("truncated minimizers")
+		}
+		genomeCounts := make(map[string]int, numGenomes)
+		for range numGenomes {
+			genome := binary.LittleEndian.Uint32(data[offset:])
+			if genome >= header.NumGenomes {
+				return nil, fmt.Errorf("minimizer %s has genome number %d of %d genomes", decodeKmer(minimizer, k), genome, header.NumGenomes)
+			}
+			genomeCounts[index.genomes[genome]] = int(binary.LittleEndian.Uint32(data[offset+4:]))
+			offset += 8
+		}
+		index.minimizers[minimizer] = genomeCounts
+	}
+	return index, nil
+}
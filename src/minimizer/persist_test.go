@@ -0,0 +1,81 @@
+package minimizer
+
+import (
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/Asaad47/BioAlgos-Assignment1/src/internal/testutil"
+	"github.com/Asaad47/BioAlgos-Assignment1/src/iupac"
+	"github.com/Asaad47/BioAlgos-Assignment1/src/refs"
+)
+
+// TestAddGenome checks that indexing two genomes, saving and loading the
+// index and adding a third genome to it gives the index built from all three
+// at once, under every order
+func TestAddGenome(t *testing.T) {
+	random := rand.New(rand.NewPCG(1, 2))
+	shared := randomSequence(random, 3000, false)
+	references := make([]refs.Reference, 3)
+	for i, genome := range []string{
+		randomSequence(random, 20000, true) + shared,
+		shared + randomSequence(random, 20000, true),
+		randomSequence(random, 10000, true) + shared,
+	} {
+		name := fmt.Sprintf("genome%d", i+1)
+		references[i] = refs.Reference{Name: name, Path: testutil.WriteFasta(t, name+".fna", genome)}
+	}
+
+	for _, ordering := range []Ordering{Lexicographic, Hash, Random, Frequency} {
+		for _, kw := range [][2]int{{15, 10}, {31, 15}} {
+			k, w := kw[0], kw[1]
+			t.Run(fmt.Sprintf("%s/k=%d/w=%d", ordering, k, w), func(t *testing.T) {
+				// a Frequency order keeps the frequent k-mers of the genomes it was first built from
+				order := Order{Ordering: ordering, Seed: 42, Fraction: 0.01}
+				if ordering == Frequency {
+					if err := order.CountFrequent(references[:2], k, io.Discard); err != nil {
+						t.Fatal(err)
+					}
+				}
+				want, err := BuildIndex(references, k, w, order, iupac.Expand, iupac.DefaultMaxExpansions, io.Discard)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				first, err := BuildIndex(references[:2], k, w, order, iupac.Expand, iupac.DefaultMaxExpansions, io.Discard)
+				if err != nil {
+					t.Fatal(err)
+				}
+				path := filepath.Join(t.TempDir(), "index.mzi")
+				if err := first.Save(path); err != nil {
+					t.Fatal(err)
+				}
+				got, err := Load(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(got.minimizers, first.minimizers) {
+					t.Fatalf("loaded %d minimizers, saved %d", got.Len(), first.Len())
+				}
+				if err := got.AddGenome(references[2], iupac.Expand, iupac.DefaultMaxExpansions, io.Discard); err != nil {
+					t.Fatal(err)
+				}
+
+				if got.K() != k || got.W() != w || got.Order().String() != want.Order().String() || !slices.Equal(got.Genomes(), want.Genomes()) {
+					t.Errorf("got k=%d w=%d order %s genomes %v, want k=%d w=%d order %s genomes %v",
+						got.K(), got.W(), got.Order(), got.Genomes(), k, w, want.Order(), want.Genomes())
+				}
+				if !reflect.DeepEqual(got.minimizers, want.minimizers) {
+					t.Errorf("added genome gives %d minimizers, building all genomes %d", got.Len(), want.Len())
+				}
+				if err := got.AddGenome(references[2], iupac.Expand, iupac.DefaultMaxExpansions, io.Discard); err == nil {
+					t.Errorf("added %s twice", references[2].Name)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package minimizer
+
+// MaxK is the longest k-mer a minimizer can be, as k-mers are packed at 2 bits
+// per base into a uint64
+const MaxK = 32
+
+// baseCodes maps the lowercase bases a, c, g and t to their 2-bit codes, in
+// lexicographic order so that packed k-mers compare like their strings, and
+// every other character to -1
+var baseCodes = func() [256]int8 {
+	var codes [256]int8
+	for i := range codes {
+		codes[i] = -1
+	}
+	codes['a'], codes['c'], codes['g'], codes['t'] = 0, 1, 2, 3
+	return codes
+}()
+
+// encodeKmer packs a k-mer of lowercase bases, reporting false if it has any
+// other character
+func encodeKmer(kmer string) (uint64, bool) {
+	var packed uint64
+	for i := 0; i < len(kmer); i++ {
+		code := baseCodes[kmer[i]]
+		if code < 0 {
+			return 0, false
+		}
+		packed = packed<<2 | uint64(code)
+	}
+	return packed, true
+}
+
+// decodeKmer returns the lowercase bases of a packed k-mer
+func decodeKmer(packed uint64, k int) string {
+	kmer := make([]byte, k)
+	for i := k - 1; i >= 0; i-- {
+		kmer[i] = "acgt"[packed&3]
+		packed >>= 2
+	}
+	return string(kmer)
+}
+
+// rollKmers calls fn with the start and the packing of every k-mer of a
+// lowercase sequence, in order, rolling each k-mer into the next. K-mers with
+// a character other than a, c, g and t are reported as ambiguous.
+func rollKmers(sequence string, k int, fn func(start int, kmer uint64, ambiguous bool)) {
+	mask := ^uint64(0) >> (64 - 2*k)
+	var kmer uint64
+	lastAmbiguous := -1 // position of the last character that is not a, c, g or t
+	for end := 0; end < len(sequence); end++ {
+		code := baseCodes[sequence[end]]
+		if code < 0 {
+			lastAmbiguous = end
+			code = 0
+		}
+		kmer = (kmer<<2 | uint64(code)) & mask
+		if start := end - k + 1; start >= 0 {
+			fn(start, kmer, lastAmbiguous >= start)
+		}
+	}
+}
+
+// windowEntry is a k-mer in the window deque, its rank and the position it
+// starts at
+type windowEntry struct {
+	position int
+	kmer     uint64
+	rank     uint64
+}
+
+// windowMinimizers calls fn with the minimizer of every window of w
+// consecutive k-mers of a lowercase sequence, the k-mer of lowest rank under
+// order, and with the position it starts at. Windows are visited in order and
+// those left without any k-mer by the ambiguity policy are counted. The
+// k-mers are packed as they are rolled along the sequence, and a deque keeps
+// the k-mers of the current window that may still become its minimum, in
+// increasing rank, so each k-mer is pushed and popped at most once.
+func windowMinimizers(sequence string, k, w int, order *Order, filter *Filter, fn func(position int, minimizer uint64)) {
+	if len(sequence) < w+k-1 {
+		return
+	}
+	mask := ^uint64(0) >> (64 - 2*k)
+	deque := make([]windowEntry, w) // ring buffer holding at most w k-mers
+	head, size := 0, 0
+
+	rollKmers(sequence, k, func(start int, kmer uint64, ambiguous bool) {
+		// the k-mer of lowest rank the k-mer starting here stands for, if any
+		smallest := windowEntry{position: start, kmer: kmer, rank: order.rank(kmer, mask)}
+		exists := true
+		if ambiguous {
+			exists = false
+			filter.Resolve(sequence[start:start+k], func(variant string) {
+				packed, _ := encodeKmer(variant)
+				if rank := order.rank(packed, mask); !exists || rank < smallest.rank {
+					smallest.kmer, smallest.rank, exists = packed, rank, true
+				}
+			})
+		}
+
+		// drop the k-mers that left the window ending with this k-mer
+		window := start - w + 1
+		for size > 0 && deque[head].position < window {
+			head = (head + 1) % w
+			size--
+		}
+		if exists {
+			// k-mers of higher rank than the new one can no longer be a minimum
+			for size > 0 && deque[(head+size-1)%w].rank > smallest.rank {
+				size--
+			}
+			deque[(head+size)%w] = smallest
+			size++
+		}
+		if window < 0 {
+			return
+		}
+		if size == 0 {
+			filter.SkippedWindows++
+			return
+		}
+		fn(deque[head].position, deque[head].kmer)
+	})
+}
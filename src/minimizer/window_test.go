@@ -0,0 +1,50 @@
+package minimizer
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/Asaad47/BioAlgos-Assignment1/src/iupac"
+)
+
+// TestWindowMinimizersMatchSorting checks that, under the Lexicographic order,
+// the sliding window selects the minimizer found by sorting the k-mer strings
+// of every window and skips the same windows, under every ambiguity policy
+func TestWindowMinimizersMatchSorting(t *testing.T) {
+	random := rand.New(rand.NewPCG(1, 2))
+	sequences := []string{
+		randomSequence(random, 5000, true),
+		randomSequence(random, 5000, false),
+		"nnnnnnnnnnnnnnnnnnnnacgtacgtnnnnnnnnnnnnnnnnnnnnnnnnnnnnacgt",
+		"acgt",
+	}
+	lexicographic := &Order{Ordering: Lexicographic}
+	for _, policy := range []iupac.Policy{iupac.Break, iupac.Expand, iupac.Wildcard} {
+		for _, kw := range [][2]int{{1, 1}, {5, 1}, {5, 4}, {15, 10}, {31, 10}, {32, 25}} {
+			k, w := kw[0], kw[1]
+			t.Run(fmt.Sprintf("%s/k=%d/w=%d", policy, k, w), func(t *testing.T) {
+				for s, sequence := range sequences {
+					var sliding, sorted []string
+					slidingFilter := &Filter{Filter: iupac.Filter{Policy: policy, MaxExpansions: iupac.DefaultMaxExpansions}}
+					sortedFilter := &Filter{Filter: iupac.Filter{Policy: policy, MaxExpansions: iupac.DefaultMaxExpansions}}
+					windowMinimizers(sequence, k, w, lexicographic, slidingFilter, func(position int, minimizer uint64) {
+						sliding = append(sliding, decodeKmer(minimizer, k))
+					})
+					legacyWindowMinimizers(sequence, k, w, sortedFilter, func(minimizer string) {
+						sorted = append(sorted, minimizer)
+					})
+					if len(sliding) != len(sorted) || slidingFilter.SkippedWindows != sortedFilter.SkippedWindows {
+						t.Fatalf("sequence %d: sliding window found %d minimizers (%d windows skipped), sorting found %d (%d windows skipped)",
+							s, len(sliding), slidingFilter.SkippedWindows, len(sorted), sortedFilter.SkippedWindows)
+					}
+					for i := range sliding {
+						if sliding[i] != sorted[i] {
+							t.Fatalf("sequence %d, window %d: sliding window found minimizer %s, sorting found %s", s, i, sliding[i], sorted[i])
+						}
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,159 @@
+// Package refs loads the manifest of reference genomes that reads are
+// classified against, so genomes can be added or renamed without code changes.
+//
+// A manifest is either a JSON array of references or a tab-separated file
+// whose first line (after '#' comments) names the columns: name and path are
+// required, taxid and accession optional. Relative paths are relative to the
+// directory of the manifest.
+package refs
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DefaultManifest is the manifest the tasks read unless told otherwise
+const DefaultManifest = "references.tsv"
+
+// Reference is a reference genome
+type Reference struct {
+	Name      string `json:"name"`                // display name used in reports, e.g. "E. coli"
+	Path      string `json:"path"`                // FASTA file of the genome
+	TaxID     int    `json:"taxid,omitempty"`     // NCBI taxonomy ID, 0 if unknown
+	Accession string `json:"accession,omitempty"` // assembly accession, e.g. GCF_000005845.2
+}
+
+// Load reads a manifest, as JSON if its name ends in .json and as TSV otherwise
+func Load(path string) ([]Reference, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var references []Reference
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		references, err = ReadJSON(file)
+	} else {
+		references, err = ReadTSV(file)
+	}
+	if err == nil {
+		err = validate(references)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	for i := range references {
+		if !filepath.IsAbs(references[i].Path) {
+			references[i].Path = filepath.Join(dir, references[i].Path)
+		}
+	}
+	return references, nil
+}
+
+// ReadJSON reads a manifest in JSON
+func ReadJSON(r io.Reader) ([]Reference, error) {
+	var references []Reference
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&references); err != nil {
+		return nil, err
+	}
+	return references, nil
+}
+
+// ReadTSV reads a manifest in TSV
+func ReadTSV(r io.Reader) ([]Reference, error) {
+	var references []Reference
+	var columns []string
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if columns == nil {
+			columns = fields
+			for _, column := range columns {
+				switch column {
+				case "name", "path", "taxid", "accession":
+				default:
+					return nil, fmt.Errorf("line %d: unknown column %q", lineNum, column)
+				}
+			}
+			continue
+		}
+		if len(fields) != len(columns) {
+			return nil, fmt.Errorf("line %d: %d fields, expected %d", lineNum, len(fields), len(columns))
+		}
+
+		var reference Reference
+		for i, column := range columns {
+			value := strings.TrimSpace(fields[i])
+			switch column {
+			case "name":
+				reference.Name = value
+			case "path":
+				reference.Path = value
+			case "taxid":
+				if value == "" {
+					continue
+				}
+				taxID, err := strconv.Atoi(value)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: taxid: %w", lineNum, err)
+				}
+				reference.TaxID = taxID
+			case "accession":
+				reference.Accession = value
+			}
+		}
+		references = append(references, reference)
+	}
+	return references, scanner.Err()
+}
+
+// validate checks that every reference has a unique name and a path
+func validate(references []Reference) error {
+	if len(references) == 0 {
+		return fmt.Errorf("no references")
+	}
+	names := make(map[string]bool)
+	for i, reference := range references {
+		if reference.Name == "" || reference.Path == "" {
+			return fmt.Errorf("reference %d: name and path are required", i+1)
+		}
+		if names[reference.Name] {
+			return fmt.Errorf("reference %d: duplicate name %q", i+1, reference.Name)
+		}
+		names[reference.Name] = true
+	}
+	return nil
+}
+
+// Names returns the names of the references, in manifest order
+func Names(references []Reference) []string {
+	names := make([]string, len(references))
+	for i, reference := range references {
+		names[i] = reference.Name
+	}
+	return names
+}
+
+// Paths returns the paths of the references, in manifest order
+func Paths(references []Reference) []string {
+	paths := make([]string, len(references))
+	for i, reference := range references {
+		paths[i] = reference.Path
+	}
+	return paths
+}
@@ -0,0 +1,188 @@
+package seqio
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+)
+
+// magic numbers at the start of compressed files
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	bzip2Magic = []byte("BZh")
+	zstdMagic  = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// ErrUnsupportedCompression is returned for compressed input that cannot be
+// decompressed by this package
+var ErrUnsupportedCompression = errors.New("unsupported compression")
+
+// decompressBlockSize is the size of the blocks passed from the decompressing
+// goroutine to the reader, and decompressBlocks the number of blocks it may
+// get ahead
+const (
+	decompressBlockSize = 1 << 20
+	decompressBlocks    = 4
+)
+
+// compressedExtensions are tried by Open when a file does not exist
+var compressedExtensions = []string{".gz", ".bz2"}
+
+// Open opens a sequence file for reading, decompressing it if it is gzip
+// (including multi-member files such as bgzip output) or bzip2 compressed.
+// The format is detected from the first bytes of the file, not its name. If
+// path does not exist but path.gz or path.bz2 does, that file is read instead.
+func Open(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	for _, ext := range compressedExtensions {
+		if !errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+		if compressed, compressedErr := os.Open(path + ext); compressedErr == nil {
+			file, err, path = compressed, nil, path+ext
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	r, err := NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &fileReader{ReadCloser: r, file: file}, nil
+}
+
+// NewReader returns a reader of the decompressed content of r if it is gzip or
+// bzip2 compressed, and of r itself otherwise. Compressed input is
+// decompressed in a separate goroutine, so decompression overlaps with the
+// parsing done by the caller. The returned reader must be closed to stop that
+// goroutine; closing it does not close r.
+func NewReader(r io.Reader) (io.ReadCloser, error) {
+	buffered := bufio.NewReaderSize(r, 1<<16)
+	magic, err := buffered.Peek(4)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, err
+		}
+		return newAsyncReader(gz), nil
+	case bytes.HasPrefix(magic, bzip2Magic):
+		return newAsyncReader(bzip2.NewReader(buffered)), nil
+	case bytes.HasPrefix(magic, zstdMagic):
+		return nil, fmt.Errorf("%w: zstd (decompress it first, e.g. with zstd -d)", ErrUnsupportedCompression)
+	}
+	return io.NopCloser(buffered), nil
+}
+
+// fileReader closes the underlying file along with its reader
+type fileReader struct {
+	io.ReadCloser
+	file *os.File
+}
+
+func (r *fileReader) Close() error {
+	err := r.ReadCloser.Close()
+	if fileErr := r.file.Close(); err == nil {
+		err = fileErr
+	}
+	return err
+}
+
+// asyncReader reads ahead from a reader in its own goroutine, passing the data
+// on in blocks through a channel
+type asyncReader struct {
+	blocks  chan []byte
+	free    chan []byte // blocks returned by Read for reuse
+	done    chan struct{}
+	err     error // error that ended the input, set before blocks is closed
+	current []byte
+	block   []byte // block holding current
+}
+
+func newAsyncReader(r io.Reader) *asyncReader {
+	a := &asyncReader{
+		blocks: make(chan []byte, decompressBlocks),
+		free:   make(chan []byte, decompressBlocks+2),
+		done:   make(chan struct{}),
+	}
+	go a.readAhead(r)
+	return a
+}
+
+func (a *asyncReader) readAhead(r io.Reader) {
+	defer close(a.blocks)
+	for {
+		var block []byte
+		select {
+		case block = <-a.free:
+		default:
+			block = make([]byte, decompressBlockSize)
+		}
+		block = block[:cap(block)]
+		n := 0
+		var err error
+		for n < len(block) && err == nil {
+			var read int
+			read, err = r.Read(block[n:])
+			n += read
+		}
+		if n > 0 {
+			select {
+			case a.blocks <- block[:n]:
+			case <-a.done:
+				return
+			}
+		}
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			a.err = err
+			return
+		}
+	}
+}
+
+func (a *asyncReader) Read(p []byte) (int, error) {
+	if len(a.current) == 0 {
+		if a.block != nil {
+			select {
+			case a.free <- a.block:
+			default:
+			}
+			a.block = nil
+		}
+		block, ok := <-a.blocks
+		if !ok {
+			if a.err != nil {
+				return 0, a.err
+			}
+			return 0, io.EOF
+		}
+		a.block, a.current = block, block
+	}
+	n := copy(p, a.current)
+	a.current = a.current[n:]
+	return n, nil
+}
+
+// Close stops the reading goroutine
+func (a *asyncReader) Close() error {
+	select {
+	case <-a.done:
+	default:
+		close(a.done)
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package seqio
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testdata/genome.fna.bz2 and testdata/reads.fastq.bz2 hold these texts,
+// compressed with bzip2 -9
+const (
+	genomeText = ">chr1 first record\nACGTACGTAC\nGTTTGCA\n>plasmid\nNNACGT\n"
+	readsText  = "@read1/1 sample\nACGTAC\n+\nIIIIII\n@read2/1\nGGCCTTA\n+\n@@@@III\n"
+)
+
+// gzipped returns text compressed as one gzip member per part
+func gzipped(t testing.TB, parts ...string) []byte {
+	var compressed bytes.Buffer
+	for _, part := range parts {
+		writer := gzip.NewWriter(&compressed)
+		if _, err := writer.Write([]byte(part)); err != nil {
+			t.Fatal(err)
+		}
+		if err := writer.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return compressed.Bytes()
+}
+
+// writeFile writes data to a file of the given name in a temporary directory
+// and returns its path
+func writeFile(t *testing.T, name string, data []byte) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// readFile reads the FASTA or FASTQ records of a file opened by Open and
+// returns them as "name sequence" lines
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	file, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var records strings.Builder
+	if strings.Contains(path, ".fastq") {
+		reader := NewFastqReader(file)
+		for {
+			record, err := reader.ReadRecord()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			records.WriteString(record.Name + " " + string(record.Sequence) + " " + string(record.Quality) + "\n")
+		}
+		return records.String()
+	}
+	reader := NewFastaReader(file)
+	for {
+		record, err := reader.ReadRecord()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		records.WriteString(record.Name + " " + string(record.Sequence) + "\n")
+	}
+	return records.String()
+}
+
+func TestOpenCompressed(t *testing.T) {
+	bzip2Genome, err := os.ReadFile("testdata/genome.fna.bz2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bzip2Reads, err := os.ReadFile("testdata/reads.fastq.bz2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantGenome := "chr1 ACGTACGTACGTTTGCA\nplasmid NNACGT\n"
+	wantReads := "read1/1 ACGTAC IIIIII\nread2/1 GGCCTTA @@@@III\n"
+	tests := []struct {
+		name string
+		file string
+		data []byte
+		want string
+	}{
+		{"plain FASTA", "genome.fna", []byte(genomeText), wantGenome},
+		{"gzip FASTA", "genome.fna.gz", gzipped(t, genomeText), wantGenome},
+		{"bgzip FASTA", "genome.fna.gz", gzipped(t, genomeText[:25], genomeText[25:40], genomeText[40:]), wantGenome},
+		{"bzip2 FASTA", "genome.fna.bz2", bzip2Genome, wantGenome},
+		{"gzip without extension", "genome.fna", gzipped(t, genomeText), wantGenome},
+		{"plain FASTQ", "reads.fastq", []byte(readsText), wantReads},
+		{"gzip FASTQ", "reads.fastq.gz", gzipped(t, readsText), wantReads},
+		{"bgzip FASTQ", "reads.fastq.gz", gzipped(t, readsText[:30], readsText[30:]), wantReads},
+		{"bzip2 FASTQ", "reads.fastq.bz2", bzip2Reads, wantReads},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := readFile(t, writeFile(t, test.file, test.data)); got != test.want {
+				t.Errorf("read %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestOpenFallback(t *testing.T) {
+	gzipPath := writeFile(t, "genome.fna.gz", gzipped(t, genomeText))
+	if got := readFile(t, strings.TrimSuffix(gzipPath, ".gz")); got != "chr1 ACGTACGTACGTTTGCA\nplasmid NNACGT\n" {
+		t.Errorf("read %q through the .gz fallback", got)
+	}
+
+	bzip2Reads, err := os.ReadFile("testdata/reads.fastq.bz2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bzip2Path := writeFile(t, "reads.fastq.bz2", bzip2Reads)
+	if got := readFile(t, strings.TrimSuffix(bzip2Path, ".bz2")); got != "read1/1 ACGTAC IIIIII\nread2/1 GGCCTTA @@@@III\n" {
+		t.Errorf("read %q through the .bz2 fallback", got)
+	}
+
+	if _, err := Open(filepath.Join(t.TempDir(), "missing.fna")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("opening a missing file returned %v, want %v", err, os.ErrNotExist)
+	}
+}
+
+func TestOpenUnsupported(t *testing.T) {
+	zstd := writeFile(t, "genome.fna.zst", append([]byte{0x28, 0xb5, 0x2f, 0xfd}, "compressed"...))
+	if _, err := Open(zstd); !errors.Is(err, ErrUnsupportedCompression) || !strings.Contains(err.Error(), zstd) {
+		t.Errorf("opening a zstd file returned %v, want %v naming the file", err, ErrUnsupportedCompression)
+	}
+}
+
+// TestReadLarge reads gzip input spanning many read-ahead blocks, and a
+// truncated copy of it, which must fail instead of ending early
+func TestReadLarge(t *testing.T) {
+	random := rand.New(rand.NewPCG(1, 2))
+	text := make([]byte, 6*decompressBlockSize+123)
+	for i := range text {
+		text[i] = "acgt\n"[random.IntN(5)]
+	}
+	compressed := gzipped(t, string(text))
+
+	reader, err := NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil || !bytes.Equal(got, text) {
+		t.Errorf("read %d bytes with error %v, want %d bytes", len(got), err, len(text))
+	}
+
+	reader, err = NewReader(bytes.NewReader(compressed[:len(compressed)/2]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	if _, err := io.ReadAll(reader); err == nil {
+		t.Error("read truncated gzip input without an error")
+	}
+}
+
+// TestCloseStopsReadAhead closes readers before the end of their input and
+// checks that their read-ahead goroutines exit
+func TestCloseStopsReadAhead(t *testing.T) {
+	compressed := gzipped(t, strings.Repeat("acgtacgtac\n", decompressBlockSize))
+	before := runtime.NumGoroutine()
+	for range 10 {
+		reader, err := NewReader(bytes.NewReader(compressed))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := reader.Read(make([]byte, 100)); err != nil {
+			t.Fatal(err)
+		}
+		reader.Close()
+	}
+	for deadline := time.Now().Add(5 * time.Second); runtime.NumGoroutine() > before; {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d goroutines still running after Close, %d before", runtime.NumGoroutine(), before)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
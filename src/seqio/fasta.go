@@ -0,0 +1,188 @@
+// Package seqio reads sequence files independently of how their lines are
+// wrapped: line breaks (LF or CRLF), blank lines and lines of any length are
+// all handled by the readers, so callers only see records and their bases.
+// The FASTQ reader checks every record and reports malformed ones with their
+// line number. Open and NewReader decompress gzip, bgzip and bzip2 input on the
+// fly, so compressed files need not be unpacked.
+package seqio
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+)
+
+// Record is a FASTA record read whole
+type Record struct {
+	Name        string // first word of the header line
+	Description string // rest of the header line
+	Sequence    []byte // bases of all sequence lines, without line breaks
+}
+
+// FastaReader reads the records of a FASTA file. Records can be read whole
+// with ReadRecord, or streamed: Next moves to the next record and Read returns
+// its bases in chunks, so a record never has to be held in memory.
+//
+// Sequence data before the first header is returned as a record with an empty
+// name, so a plain sequence file reads as a single record.
+type FastaReader struct {
+	r           *bufio.Reader
+	name        string
+	description string
+	inRecord    bool   // Read returns bases of the current record
+	atLineStart bool   // the next byte read starts a new line
+	pending     []byte // part of the current line not yet returned by Read
+	err         error
+}
+
+func NewFastaReader(r io.Reader) *FastaReader {
+	return &FastaReader{r: bufio.NewReaderSize(r, 1<<16), atLineStart: true}
+}
+
+// Next skips the rest of the current record and moves to the next one. It
+// returns false at the end of the input or on an error, reported by Err.
+func (r *FastaReader) Next() bool {
+	if r.err != nil {
+		return false
+	}
+	if r.inRecord {
+		if _, err := io.Copy(io.Discard, r); err != nil {
+			return false
+		}
+	}
+
+	for {
+		c, err := r.r.ReadByte()
+		if err != nil {
+			if err != io.EOF {
+				r.err = err
+			}
+			return false
+		}
+		switch c {
+		case '\n', '\r', ' ', '\t':
+			continue
+		case '>':
+			return r.readHeader()
+		}
+		// sequence data before the first header
+		r.r.UnreadByte()
+		r.name, r.description = "", ""
+		r.inRecord, r.atLineStart = true, false
+		return true
+	}
+}
+
+// readHeader reads the rest of a header line after its '>'
+func (r *FastaReader) readHeader() bool {
+	header, err := r.r.ReadString('\n')
+	if err != nil && err != io.EOF {
+		r.err = err
+		return false
+	}
+	r.name, r.description = splitHeader(header)
+	r.inRecord, r.atLineStart = true, true
+	return true
+}
+
+// splitHeader splits a header line after its '>' or '@' into the record name,
+// its first word, and the description that follows
+func splitHeader(header string) (string, string) {
+	header = strings.TrimSpace(header)
+	if i := strings.IndexAny(header, " \t"); i >= 0 {
+		return header[:i], strings.TrimSpace(header[i:])
+	}
+	return header, ""
+}
+
+// Name returns the name of the current record, the first word of its header
+func (r *FastaReader) Name() string {
+	return r.name
+}
+
+// Description returns the header of the current record after its name
+func (r *FastaReader) Description() string {
+	return r.description
+}
+
+// Read reads bases of the current record into p, skipping line breaks and
+// other whitespace. It returns io.EOF at the end of the record.
+func (r *FastaReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) && r.inRecord {
+		if len(r.pending) == 0 {
+			if err := r.fill(); err != nil {
+				return n, err
+			}
+			continue
+		}
+		switch r.pending[0] {
+		case '\n', '\r', ' ', '\t':
+			r.pending = r.pending[1:]
+			continue
+		}
+		end := bytes.IndexAny(r.pending, "\n\r \t")
+		if end < 0 {
+			end = len(r.pending)
+		}
+		copied := copy(p[n:], r.pending[:end])
+		n += copied
+		r.pending = r.pending[copied:]
+	}
+	if n == 0 && len(p) > 0 {
+		return 0, io.EOF
+	}
+	return n, nil
+}
+
+// fill reads the next piece of the current record's sequence into pending,
+// ending the record at the next header or at the end of the input
+func (r *FastaReader) fill() error {
+	if r.atLineStart {
+		start, err := r.r.Peek(1)
+		if err == io.EOF || err == nil && start[0] == '>' {
+			r.inRecord = false
+			return nil
+		}
+		if err != nil {
+			r.err = err
+			return err
+		}
+	}
+
+	line, err := r.r.ReadSlice('\n')
+	switch err {
+	case nil, io.EOF:
+		r.atLineStart = true
+	case bufio.ErrBufferFull:
+		// a line longer than the buffer continues in the next piece
+		r.atLineStart = false
+	default:
+		r.err = err
+		return err
+	}
+	r.pending = line
+	return nil
+}
+
+// ReadRecord reads the next record whole. It returns io.EOF when there are no
+// more records.
+func (r *FastaReader) ReadRecord() (*Record, error) {
+	if !r.Next() {
+		if r.err != nil {
+			return nil, r.err
+		}
+		return nil, io.EOF
+	}
+	sequence, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return &Record{Name: r.name, Description: r.description, Sequence: sequence}, nil
+}
+
+// Err returns the first error that stopped Next, if any
+func (r *FastaReader) Err() error {
+	return r.err
+}
@@ -0,0 +1,109 @@
+package seqio
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+// readFasta returns the records of a FASTA text read whole by ReadRecord
+func readFasta(t *testing.T, text string) []*Record {
+	t.Helper()
+	reader := NewFastaReader(strings.NewReader(text))
+	var records []*Record
+	for {
+		record, err := reader.ReadRecord()
+		if err == io.EOF {
+			return records
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+}
+
+// wrap splits a sequence into lines of width bases ended by newline
+func wrap(sequence string, width int, newline string) string {
+	var text strings.Builder
+	for start := 0; start < len(sequence); start += width {
+		text.WriteString(sequence[start:min(start+width, len(sequence))] + newline)
+	}
+	return text.String()
+}
+
+func TestFastaWrapping(t *testing.T) {
+	first := strings.Repeat("acgtn", 50)
+	second := strings.Repeat("ttgca", 31)
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"unwrapped", ">chr1 first record\n" + first + "\n>chr2\n" + second + "\n"},
+		{"wrapped at 60", ">chr1 first record\n" + wrap(first, 60, "\n") + ">chr2\n" + wrap(second, 60, "\n")},
+		{"wrapped at 1", ">chr1 first record\n" + wrap(first, 1, "\n") + ">chr2\n" + wrap(second, 1, "\n")},
+		{"CRLF", ">chr1 first record\r\n" + wrap(first, 70, "\r\n") + ">chr2\r\n" + wrap(second, 70, "\r\n")},
+		{"blank lines", "\n>chr1 first record\n\n" + wrap(first, 80, "\n\n") + "\n>chr2\n" + second},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			records := readFasta(t, test.text)
+			if len(records) != 2 {
+				t.Fatalf("read %d records, want 2", len(records))
+			}
+			if records[0].Name != "chr1" || records[0].Description != "first record" || records[1].Name != "chr2" {
+				t.Errorf("headers are %q %q and %q, want chr1, first record and chr2",
+					records[0].Name, records[0].Description, records[1].Name)
+			}
+			if string(records[0].Sequence) != first || string(records[1].Sequence) != second {
+				t.Errorf("sequences are %q and %q, want %q and %q", records[0].Sequence, records[1].Sequence, first, second)
+			}
+		})
+	}
+}
+
+// TestFastaLongLines reads lines longer than the 64 KB buffer of the reader,
+// whole and streamed in small chunks
+func TestFastaLongLines(t *testing.T) {
+	first := strings.Repeat("acgt", 50000) // 200 kbp on one line
+	second := strings.Repeat("ggc", 30000) // 90 kbp on one line
+	text := ">long1\n" + first + "\n>long2\n" + second
+
+	records := readFasta(t, text)
+	if len(records) != 2 || string(records[0].Sequence) != first || string(records[1].Sequence) != second {
+		t.Fatalf("long lines were not read back whole")
+	}
+
+	reader := NewFastaReader(strings.NewReader(text))
+	for i, want := range []string{first, second} {
+		if !reader.Next() {
+			t.Fatalf("record %d missing: %v", i+1, reader.Err())
+		}
+		var sequence bytes.Buffer
+		buffer := make([]byte, 1000)
+		for {
+			n, err := reader.Read(buffer)
+			sequence.Write(buffer[:n])
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		if sequence.String() != want {
+			t.Errorf("record %d streamed %d bases, want %d", i+1, sequence.Len(), len(want))
+		}
+	}
+	if reader.Next() {
+		t.Errorf("read a third record %q", reader.Name())
+	}
+}
+
+func TestFastaWithoutHeader(t *testing.T) {
+	records := readFasta(t, "acgt\nacgt\n")
+	if len(records) != 1 || records[0].Name != "" || string(records[0].Sequence) != "acgtacgt" {
+		t.Errorf("plain sequence read as %d records", len(records))
+	}
+}
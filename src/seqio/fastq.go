@@ -0,0 +1,230 @@
+package seqio
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// phredOffset is the character encoding a quality score of zero (Phred+33)
+const phredOffset = '!'
+
+// FastqRecord is a FASTQ record. Sequence and quality may each span several
+// lines in the file; here they are joined.
+type FastqRecord struct {
+	Name        string // first word of the header line
+	Description string // rest of the header line
+	Sequence    []byte
+	Quality     []byte // one Phred+33 character per base of Sequence
+}
+
+// Header returns the header line of the record without its '@'
+func (r *FastqRecord) Header() string {
+	if r.Description == "" {
+		return r.Name
+	}
+	return r.Name + " " + r.Description
+}
+
+// Qualities returns the Phred quality score of each base
+func (r *FastqRecord) Qualities() []uint8 {
+	scores := make([]uint8, len(r.Quality))
+	for i, char := range r.Quality {
+		scores[i] = char - phredOffset
+	}
+	return scores
+}
+
+// Errors wrapped by a ParseError
+var (
+	ErrMissingHeader    = errors.New("record does not start with '@'")
+	ErrMissingSeparator = errors.New("sequence is not followed by a '+' line")
+	ErrSeparatorHeader  = errors.New("'+' line does not repeat the record header")
+	ErrQualityLength    = errors.New("quality and sequence lengths differ")
+	ErrQualityRange     = errors.New("quality character outside '!' to '~'")
+	ErrTruncated        = errors.New("file ends inside a record")
+)
+
+// ParseError reports a malformed FASTQ record and the line it was found on
+type ParseError struct {
+	Line int // 1-based line number
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// FastqReader reads the records of a FASTQ file, validating each one. Blank
+// lines between records are ignored, and sequence and quality may be wrapped
+// over several lines.
+type FastqReader struct {
+	// SkipMalformed makes ReadRecord skip malformed records, resuming at the
+	// next line starting with '@', instead of returning a *ParseError
+	SkipMalformed bool
+
+	r        *bufio.Reader
+	line     int    // number of the last line read
+	lineBuf  []byte // last line read, without its line break
+	unread   bool   // readLine returns lineBuf again
+	skipped  int
+	firstErr *ParseError
+}
+
+func NewFastqReader(r io.Reader) *FastqReader {
+	return &FastqReader{r: bufio.NewReaderSize(r, 1<<16)}
+}
+
+// Skipped returns the number of malformed records skipped so far
+func (r *FastqReader) Skipped() int {
+	return r.skipped
+}
+
+// FirstSkipped returns the error of the first malformed record skipped, if any
+func (r *FastqReader) FirstSkipped() *ParseError {
+	return r.firstErr
+}
+
+// ReadRecord reads the next record. It returns io.EOF when there are no more
+// records, and a *ParseError for a malformed record unless SkipMalformed is set.
+func (r *FastqReader) ReadRecord() (*FastqRecord, error) {
+	for {
+		record, err := r.parseRecord()
+		var parseErr *ParseError
+		if !r.SkipMalformed || !errors.As(err, &parseErr) {
+			return record, err
+		}
+		r.skipped++
+		if r.firstErr == nil {
+			r.firstErr = parseErr
+		}
+		if err := r.resync(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (r *FastqReader) parseRecord() (*FastqRecord, error) {
+	header, err := r.readLine()
+	for err == nil && len(bytes.TrimSpace(header)) == 0 {
+		header, err = r.readLine()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if header[0] != '@' {
+		return nil, r.parseError(ErrMissingHeader)
+	}
+	record := &FastqRecord{}
+	record.Name, record.Description = splitHeader(string(header[1:]))
+
+	// sequence lines up to the '+' separator
+	for {
+		line, err := r.readLine()
+		if err == io.EOF {
+			return nil, r.parseError(ErrTruncated)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(line) > 0 && line[0] == '+' {
+			if title := bytes.TrimSpace(line[1:]); len(title) > 0 && string(title) != record.Header() {
+				return nil, r.parseError(ErrSeparatorHeader)
+			}
+			break
+		}
+		if len(line) > 0 && line[0] == '@' {
+			// most likely the header of the next record
+			r.unreadLine()
+			return nil, r.parseError(ErrMissingSeparator)
+		}
+		record.Sequence = append(record.Sequence, bytes.TrimSpace(line)...)
+	}
+
+	// quality lines until they cover the sequence; a quality line may start
+	// with '@', so lengths rather than headers delimit the record
+	for len(record.Quality) < len(record.Sequence) {
+		line, err := r.readLine()
+		if err == io.EOF {
+			return nil, r.parseError(ErrTruncated)
+		}
+		if err != nil {
+			return nil, err
+		}
+		line = bytes.TrimSpace(line)
+		if len(record.Quality) > 0 && len(line) > 0 && line[0] == '@' && len(record.Quality)+len(line) > len(record.Sequence) {
+			// too long to continue the quality: the header of the next record
+			r.unreadLine()
+			break
+		}
+		record.Quality = append(record.Quality, line...)
+	}
+	if len(record.Quality) != len(record.Sequence) {
+		return nil, r.parseError(ErrQualityLength)
+	}
+	for _, char := range record.Quality {
+		if char < '!' || char > '~' {
+			return nil, r.parseError(ErrQualityRange)
+		}
+	}
+	return record, nil
+}
+
+// resync skips lines up to the next one starting with '@'
+func (r *FastqReader) resync() error {
+	for {
+		line, err := r.readLine()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(line) > 0 && line[0] == '@' {
+			r.unreadLine()
+			return nil
+		}
+	}
+}
+
+// readLine returns the next line without its line break (LF or CRLF). The
+// line is only valid until the next call.
+func (r *FastqReader) readLine() ([]byte, error) {
+	if r.unread {
+		r.unread = false
+		r.line++
+		return r.lineBuf, nil
+	}
+	r.lineBuf = r.lineBuf[:0]
+	for {
+		chunk, err := r.r.ReadSlice('\n')
+		r.lineBuf = append(r.lineBuf, chunk...)
+		if err == bufio.ErrBufferFull {
+			// a line longer than the buffer
+			continue
+		}
+		if err != nil && (err != io.EOF || len(r.lineBuf) == 0) {
+			return nil, err
+		}
+		break
+	}
+	r.line++
+	r.lineBuf = bytes.TrimRight(r.lineBuf, "\r\n")
+	return r.lineBuf, nil
+}
+
+// unreadLine makes readLine return the last line again
+func (r *FastqReader) unreadLine() {
+	r.unread = true
+	r.line--
+}
+
+func (r *FastqReader) parseError(err error) *ParseError {
+	return &ParseError{Line: r.line, Err: err}
+}
@@ -0,0 +1,123 @@
+package seqio
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// readFastq returns the records of a FASTQ text and the error that stopped
+// the reading, nil at the end of the text
+func readFastq(text string, skipMalformed bool) ([]*FastqRecord, *FastqReader, error) {
+	reader := NewFastqReader(strings.NewReader(text))
+	reader.SkipMalformed = skipMalformed
+	var records []*FastqRecord
+	for {
+		record, err := reader.ReadRecord()
+		if err == io.EOF {
+			return records, reader, nil
+		}
+		if err != nil {
+			return records, reader, err
+		}
+		records = append(records, record)
+	}
+}
+
+func TestFastqRecords(t *testing.T) {
+	type read struct{ name, sequence, quality string }
+	tests := []struct {
+		name string
+		text string
+		want []read
+	}{
+		{
+			"one line each",
+			"@r1 desc\nACGT\n+\nIIII\n@r2\nGGCC\n+r2\n!!!!\n",
+			[]read{{"r1", "ACGT", "IIII"}, {"r2", "GGCC", "!!!!"}},
+		},
+		{
+			"wrapped",
+			"@r1\nACGT\nACG\n+\nIIII\nIII\n@r2\nTT\n+\n##\n",
+			[]read{{"r1", "ACGTACG", "IIIIIII"}, {"r2", "TT", "##"}},
+		},
+		{
+			"quality starting with @",
+			"@r1\nACGT\n+\n@III\n@r2\nACGT\n+\n@@@@\n",
+			[]read{{"r1", "ACGT", "@III"}, {"r2", "ACGT", "@@@@"}},
+		},
+		{
+			"wrapped quality line starting with @",
+			"@r1\nACGTAC\n+\nIII\n@II\n@r2\nAC\n+\nII\n",
+			[]read{{"r1", "ACGTAC", "III@II"}, {"r2", "AC", "II"}},
+		},
+		{
+			"CRLF and blank lines",
+			"@r1\r\nACGT\r\n+\r\nIIII\r\n\r\n\n@r2\r\nA\r\n+\r\n5\r\n",
+			[]read{{"r1", "ACGT", "IIII"}, {"r2", "A", "5"}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			records, _, err := readFastq(test.text, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(records) != len(test.want) {
+				t.Fatalf("read %d records, want %d", len(records), len(test.want))
+			}
+			for i, want := range test.want {
+				got := records[i]
+				if got.Name != want.name || string(got.Sequence) != want.sequence || string(got.Quality) != want.quality {
+					t.Errorf("record %d is %s %s %s, want %s %s %s", i+1,
+						got.Name, got.Sequence, got.Quality, want.name, want.sequence, want.quality)
+				}
+			}
+		})
+	}
+}
+
+func TestFastqMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		err  error
+		line int
+	}{
+		{"missing header", "r1\nACGT\n+\nIIII\n", ErrMissingHeader, 1},
+		{"missing separator", "@r1\nACGT\n@r2\nACGT\n+\nIIII\n", ErrMissingSeparator, 2},
+		{"separator header", "@r1\nACGT\n+r2\nIIII\n", ErrSeparatorHeader, 3},
+		{"short quality", "@r1\nACGT\n+\nIII\n", ErrTruncated, 4},
+		{"long quality", "@r1\nACGT\n+\nIIIII\n", ErrQualityLength, 4},
+		{"quality range", "@r1\nACGT\n+\nII I\n", ErrQualityRange, 4},
+		{"truncated", "@r1\nACGT\n", ErrTruncated, 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, err := readFastq(test.text, false)
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) || !errors.Is(err, test.err) || parseErr.Line != test.line {
+				t.Errorf("got error %v, want %v on line %d", err, test.err, test.line)
+			}
+		})
+	}
+}
+
+func TestFastqSkipMalformed(t *testing.T) {
+	text := "@r1\nACGT\n+\nIIII\n@bad\nACGT\n+\nII\n@r2\nGG\n+\nII\nnoise\n@r3\nT\n+\n!\n"
+	records, reader, err := readFastq(text, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, record := range records {
+		names = append(names, record.Name)
+	}
+	if strings.Join(names, ",") != "r1,r2,r3" {
+		t.Errorf("read records %v, want r1, r2 and r3", names)
+	}
+	if reader.Skipped() != 2 || reader.FirstSkipped() == nil || !errors.Is(reader.FirstSkipped(), ErrQualityLength) {
+		t.Errorf("skipped %d records, first %v, want 2 records, first with %v", reader.Skipped(), reader.FirstSkipped(), ErrQualityLength)
+	}
+}
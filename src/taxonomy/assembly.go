@@ -0,0 +1,123 @@
+package taxonomy
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Assembly is a genome assembly listed in an NCBI assembly summary
+type Assembly struct {
+	Accession    string // e.g. GCF_000005845.2
+	TaxID        int
+	SpeciesTaxID int
+	OrganismName string
+	AssemblyName string // e.g. ASM584v2
+}
+
+// Assemblies maps assembly accessions to assemblies
+type Assemblies map[string]*Assembly
+
+// ReadAssemblySummary reads an NCBI assembly_summary.txt file: tab-separated
+// columns named by the last comment line before the first assembly
+func ReadAssemblySummary(r io.Reader) (Assemblies, error) {
+	assemblies := make(Assemblies)
+	columns := make(map[string]int)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 1<<16), 1<<20)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") {
+			header := strings.Split(strings.TrimSpace(strings.TrimPrefix(line, "#")), "\t")
+			if header[0] == "assembly_accession" {
+				for i, column := range header {
+					columns[column] = i
+				}
+			}
+			continue
+		}
+		if line == "" {
+			continue
+		}
+		if len(columns) == 0 {
+			return nil, fmt.Errorf("assembly summary line %d: no header line naming the columns", lineNum)
+		}
+
+		fields := strings.Split(line, "\t")
+		field := func(column string) string {
+			if i, exists := columns[column]; exists && i < len(fields) {
+				return fields[i]
+			}
+			return ""
+		}
+		assembly := &Assembly{
+			Accession:    field("assembly_accession"),
+			OrganismName: field("organism_name"),
+			AssemblyName: field("asm_name"),
+		}
+		var err error
+		if assembly.TaxID, err = strconv.Atoi(field("taxid")); err != nil {
+			return nil, fmt.Errorf("assembly summary line %d: taxid: %w", lineNum, err)
+		}
+		if assembly.SpeciesTaxID, err = strconv.Atoi(field("species_taxid")); err != nil {
+			return nil, fmt.Errorf("assembly summary line %d: species_taxid: %w", lineNum, err)
+		}
+		assemblies[assembly.Accession] = assembly
+	}
+	return assemblies, scanner.Err()
+}
+
+// LoadAssemblySummary reads an NCBI assembly_summary.txt file
+func LoadAssemblySummary(path string) (Assemblies, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	assemblies, err := ReadAssemblySummary(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return assemblies, nil
+}
+
+// BuiltinAssemblies returns the assemblies of the five reference genomes
+func BuiltinAssemblies() Assemblies {
+	summary, _ := data.Open("data/assembly_summary.txt")
+	assemblies, err := ReadAssemblySummary(summary)
+	if err != nil {
+		panic("taxonomy: built-in assembly summary: " + err.Error())
+	}
+	return assemblies
+}
+
+// Find returns the assembly with an accession, or failing that the latest
+// listed version of the accession
+func (a Assemblies) Find(accession string) (*Assembly, bool) {
+	if assembly, exists := a[accession]; exists {
+		return assembly, true
+	}
+	base, _, _ := strings.Cut(accession, ".")
+	var latest *Assembly
+	latestVersion := -1
+	for _, assembly := range a {
+		name, version, _ := strings.Cut(assembly.Accession, ".")
+		if number, err := strconv.Atoi(version); name == base && err == nil && number > latestVersion {
+			latest, latestVersion = assembly, number
+		}
+	}
+	return latest, latest != nil
+}
+
+var accessionPattern = regexp.MustCompile(`GC[AF]_[0-9]{9}\.[0-9]+`)
+
+// AccessionFromPath returns the assembly accession in a file path, such as
+// GCF_000005845.2 in .../GCF_000005845.2_ASM584v2_genomic.fna, or "" if there
+// is none
+func AccessionFromPath(path string) string {
+	return accessionPattern.FindString(path)
+}
@@ -0,0 +1,272 @@
+// Package taxonomy reads the NCBI taxonomy from a taxdump (nodes.dmp and
+// names.dmp) and answers LCA, rank and lineage queries on it. It also maps
+// assembly accessions to taxa through an NCBI assembly_summary.txt file.
+// Builtin and BuiltinAssemblies return a subset of these files covering the
+// five reference genomes, embedded from the data directory.
+package taxonomy
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Root is the taxonomy ID of the root of the tree
+const Root = 1
+
+// data holds a subset of the NCBI taxonomy and assembly summary covering the
+// five reference genomes, in the same formats as the NCBI files
+//
+//go:embed data/nodes.dmp data/names.dmp data/assembly_summary.txt
+var data embed.FS
+
+// Taxonomy is a tree of taxa keyed by NCBI taxonomy ID
+type Taxonomy struct {
+	parent   map[int]int // the root is its own parent
+	rank     map[int]string
+	name     map[int]string // scientific name
+	children map[int][]int
+}
+
+// Load reads a taxonomy from the contents of nodes.dmp and names.dmp
+func Load(nodes, names io.Reader) (*Taxonomy, error) {
+	t := &Taxonomy{
+		parent: make(map[int]int),
+		rank:   make(map[int]string),
+		name:   make(map[int]string),
+	}
+	err := readDmp(nodes, "nodes.dmp", 3, func(fields []string) error {
+		id, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return err
+		}
+		parent, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return err
+		}
+		t.parent[id] = parent
+		t.rank[id] = fields[2]
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = readDmp(names, "names.dmp", 4, func(fields []string) error {
+		if fields[3] != "scientific name" {
+			return nil
+		}
+		id, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return err
+		}
+		t.name[id] = fields[1]
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, exists := t.parent[Root]; !exists {
+		return nil, fmt.Errorf("nodes.dmp: no root taxon %d", Root)
+	}
+	t.children = make(map[int][]int)
+	for id, parent := range t.parent {
+		if _, exists := t.parent[parent]; !exists {
+			return nil, fmt.Errorf("nodes.dmp: parent %d of taxon %d is missing", parent, id)
+		}
+		if id != Root {
+			t.children[parent] = append(t.children[parent], id)
+		}
+	}
+	for _, children := range t.children {
+		slices.Sort(children)
+	}
+
+	// walks towards the root would never end on a cycle, so every taxon must reach it
+	reachesRoot := map[int]bool{Root: true}
+	for id := range t.parent {
+		var path []int
+		for taxon := id; !reachesRoot[taxon]; taxon = t.parent[taxon] {
+			if slices.Contains(path, taxon) {
+				return nil, fmt.Errorf("nodes.dmp: taxon %d does not lead to the root, its parents loop through %d", id, taxon)
+			}
+			path = append(path, taxon)
+		}
+		for _, taxon := range path {
+			reachesRoot[taxon] = true
+		}
+	}
+	return t, nil
+}
+
+// LoadDir reads a taxonomy from the nodes.dmp and names.dmp files of a
+// directory, such as an extracted taxdump archive
+func LoadDir(dir string) (*Taxonomy, error) {
+	nodes, err := os.Open(filepath.Join(dir, "nodes.dmp"))
+	if err != nil {
+		return nil, err
+	}
+	defer nodes.Close()
+	names, err := os.Open(filepath.Join(dir, "names.dmp"))
+	if err != nil {
+		return nil, err
+	}
+	defer names.Close()
+	return Load(nodes, names)
+}
+
+// Builtin returns the taxonomy of the five reference genomes
+func Builtin() *Taxonomy {
+	nodes, _ := data.Open("data/nodes.dmp")
+	names, _ := data.Open("data/names.dmp")
+	t, err := Load(nodes, names)
+	if err != nil {
+		panic("taxonomy: built-in taxdump: " + err.Error())
+	}
+	return t
+}
+
+// readDmp calls fn with the fields of every line of a dmp file, which are
+// separated by "\t|\t" and end with "\t|"
+func readDmp(r io.Reader, fileName string, minFields int, fn func(fields []string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 1<<16), 1<<20)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSuffix(strings.TrimSuffix(scanner.Text(), "\t|"), "|")
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, "\t|\t")
+		if len(fields) < minFields {
+			return fmt.Errorf("%s line %d: %d fields, expected at least %d", fileName, lineNum, len(fields), minFields)
+		}
+		if err := fn(fields); err != nil {
+			return fmt.Errorf("%s line %d: %w", fileName, lineNum, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// Len returns the number of taxa
+func (t *Taxonomy) Len() int {
+	return len(t.parent)
+}
+
+// Contains reports whether a taxon is in the taxonomy
+func (t *Taxonomy) Contains(id int) bool {
+	_, exists := t.parent[id]
+	return exists
+}
+
+// Parent returns the parent of a taxon; the root is its own parent
+func (t *Taxonomy) Parent(id int) int {
+	return t.parent[id]
+}
+
+// Rank returns the rank of a taxon, e.g. "species" or "no rank"
+func (t *Taxonomy) Rank(id int) string {
+	return t.rank[id]
+}
+
+// Name returns the scientific name of a taxon
+func (t *Taxonomy) Name(id int) string {
+	return t.name[id]
+}
+
+// Children returns the children of a taxon in increasing order of ID
+func (t *Taxonomy) Children(id int) []int {
+	return t.children[id]
+}
+
+// Depth returns the number of edges between a taxon and the root, or -1 if
+// the taxon is not in the taxonomy
+func (t *Taxonomy) Depth(id int) int {
+	if !t.Contains(id) {
+		return -1
+	}
+	depth := 0
+	for id != Root {
+		id = t.parent[id]
+		depth++
+	}
+	return depth
+}
+
+// LCA returns the lowest common ancestor of two taxa, or 0 if either is not
+// in the taxonomy
+func (t *Taxonomy) LCA(a, b int) int {
+	depthA, depthB := t.Depth(a), t.Depth(b)
+	if depthA < 0 || depthB < 0 {
+		return 0
+	}
+	for ; depthA > depthB; depthA-- {
+		a = t.parent[a]
+	}
+	for ; depthB > depthA; depthB-- {
+		b = t.parent[b]
+	}
+	for a != b {
+		a, b = t.parent[a], t.parent[b]
+	}
+	return a
+}
+
+// IsAncestor reports whether ancestor is id or one of its ancestors
+func (t *Taxonomy) IsAncestor(ancestor, id int) bool {
+	if !t.Contains(id) {
+		return false
+	}
+	for id != Root && id != ancestor {
+		id = t.parent[id]
+	}
+	return id == ancestor
+}
+
+// AtRank returns the ancestor of a taxon (or the taxon itself) with the given
+// rank, if there is one
+func (t *Taxonomy) AtRank(id int, rank string) (int, bool) {
+	if !t.Contains(id) {
+		return 0, false
+	}
+	for {
+		if t.rank[id] == rank {
+			return id, true
+		}
+		if id == Root {
+			return 0, false
+		}
+		id = t.parent[id]
+	}
+}
+
+// Path returns the taxa from the root down to a taxon, the root excluded, or
+// nil if the taxon is not in the taxonomy
+func (t *Taxonomy) Path(id int) []int {
+	if !t.Contains(id) {
+		return nil
+	}
+	var path []int
+	for ; id != Root; id = t.parent[id] {
+		path = append(path, id)
+	}
+	slices.Reverse(path)
+	return path
+}
+
+// Lineage returns the names of the taxa from the root down to a taxon,
+// separated by semicolons, e.g. "cellular organisms;Bacteria;...;Escherichia coli",
+// or "" if the taxon is not in the taxonomy
+func (t *Taxonomy) Lineage(id int) string {
+	path := t.Path(id)
+	names := make([]string, len(path))
+	for i, taxon := range path {
+		names[i] = t.name[taxon]
+	}
+	return strings.Join(names, ";")
+}
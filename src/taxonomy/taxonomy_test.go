@@ -0,0 +1,172 @@
+package taxonomy
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// unknown is a taxonomy ID that is not in the built-in taxonomy
+const unknown = 999999
+
+func TestLCA(t *testing.T) {
+	tree := Builtin()
+	tests := []struct {
+		name string
+		a, b int
+		want int
+	}{
+		{"same taxon", 83332, 83332, 83332},
+		{"ancestor", 83332, 1773, 1773},
+		{"E. coli and P. aeruginosa", 511145, 208964, 1236},
+		{"B. subtilis and S. aureus", 224308, 93061, 1385},
+		{"E. coli and B. subtilis", 511145, 224308, 2},
+		{"root", 1, 511145, 1},
+		{"unknown taxon", 511145, unknown, 0},
+		{"both unknown", unknown, unknown, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := tree.LCA(test.a, test.b); got != test.want {
+				t.Errorf("LCA(%d, %d) = %d, want %d", test.a, test.b, got, test.want)
+			}
+			if got := tree.LCA(test.b, test.a); got != test.want {
+				t.Errorf("LCA(%d, %d) = %d, want %d", test.b, test.a, got, test.want)
+			}
+		})
+	}
+}
+
+func TestAtRank(t *testing.T) {
+	tree := Builtin()
+	tests := []struct {
+		id     int
+		rank   string
+		want   int
+		exists bool
+	}{
+		{511145, "species", 562, true},
+		{511145, "strain", 511145, true},
+		{93061, "genus", 1279, true},
+		{83332, "family", 1762, true},
+		{208964, "domain", 2, true},
+		{511145, "subspecies", 0, false},
+		{unknown, "species", 0, false},
+	}
+	for _, test := range tests {
+		got, exists := tree.AtRank(test.id, test.rank)
+		if got != test.want || exists != test.exists {
+			t.Errorf("AtRank(%d, %q) = %d, %v, want %d, %v", test.id, test.rank, got, exists, test.want, test.exists)
+		}
+	}
+}
+
+func TestLineage(t *testing.T) {
+	tree := Builtin()
+	tests := []struct {
+		id   int
+		want string
+	}{
+		{562, "cellular organisms;Bacteria;Pseudomonadota;Gammaproteobacteria;Enterobacterales;Enterobacteriaceae;Escherichia;Escherichia coli"},
+		{2, "cellular organisms;Bacteria"},
+		{Root, ""},
+		{unknown, ""},
+	}
+	for _, test := range tests {
+		if got := tree.Lineage(test.id); got != test.want {
+			t.Errorf("Lineage(%d) = %q, want %q", test.id, got, test.want)
+		}
+	}
+}
+
+func TestUnknownTaxon(t *testing.T) {
+	tree := Builtin()
+	if depth := tree.Depth(unknown); depth != -1 {
+		t.Errorf("Depth(%d) = %d, want -1", unknown, depth)
+	}
+	if path := tree.Path(unknown); path != nil {
+		t.Errorf("Path(%d) = %v, want nil", unknown, path)
+	}
+	if tree.IsAncestor(Root, unknown) {
+		t.Errorf("IsAncestor(%d, %d) = true, want false", Root, unknown)
+	}
+	if !tree.IsAncestor(1236, 511145) || tree.IsAncestor(511145, 1236) {
+		t.Errorf("IsAncestor is wrong for Gammaproteobacteria and E. coli K-12 MG1655")
+	}
+}
+
+func TestPath(t *testing.T) {
+	tree := Builtin()
+	want := []int{131567, 2, 1783272, 201174, 1760, 85007, 1762, 1763, 77643, 1773, 83332}
+	if path := tree.Path(83332); !slices.Equal(path, want) {
+		t.Errorf("Path(83332) = %v, want %v", path, want)
+	}
+	if depth := tree.Depth(83332); depth != len(want) {
+		t.Errorf("Depth(83332) = %d, want %d", depth, len(want))
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		nodes string
+	}{
+		{"no root", "2\t|\t2\t|\tdomain\t|\n"},
+		{"missing parent", "1\t|\t1\t|\tno rank\t|\n2\t|\t3\t|\tdomain\t|\n"},
+		{"cycle", "1\t|\t1\t|\tno rank\t|\n2\t|\t1\t|\tdomain\t|\n5\t|\t6\t|\tgenus\t|\n6\t|\t5\t|\tfamily\t|\n"},
+		{"cycle below a valid taxon", "1\t|\t1\t|\tno rank\t|\n5\t|\t6\t|\tgenus\t|\n6\t|\t7\t|\tfamily\t|\n7\t|\t6\t|\torder\t|\n8\t|\t5\t|\tspecies\t|\n"},
+		{"self loop", "1\t|\t1\t|\tno rank\t|\n7\t|\t7\t|\tgenus\t|\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Load(strings.NewReader(test.nodes), strings.NewReader("")); err == nil {
+				t.Errorf("loaded an invalid taxonomy")
+			}
+		})
+	}
+
+	valid := "1\t|\t1\t|\tno rank\t|\n2\t|\t1\t|\tdomain\t|\n5\t|\t2\t|\tgenus\t|\n6\t|\t5\t|\tspecies\t|\n"
+	tree, err := Load(strings.NewReader(valid), strings.NewReader(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree.Len() != 4 || tree.LCA(6, 2) != 2 {
+		t.Errorf("loaded %d taxa with LCA(6, 2) = %d, want 4 taxa and 2", tree.Len(), tree.LCA(6, 2))
+	}
+}
+
+func TestAssembliesFind(t *testing.T) {
+	builtin := BuiltinAssemblies()
+	versions := Assemblies{
+		"GCF_000001405.39": {Accession: "GCF_000001405.39", TaxID: 9606},
+		"GCF_000001405.40": {Accession: "GCF_000001405.40", TaxID: 9606},
+		"GCF_000001405.9":  {Accession: "GCF_000001405.9", TaxID: 9606},
+	}
+	tests := []struct {
+		name       string
+		assemblies Assemblies
+		accession  string
+		want       string // accession of the assembly found, "" if none
+	}{
+		{"exact", builtin, "GCF_000005845.2", "GCF_000005845.2"},
+		{"older version", builtin, "GCF_000005845.1", "GCF_000005845.2"},
+		{"newer version", builtin, "GCF_000195955.3", "GCF_000195955.2"},
+		{"latest of several", versions, "GCF_000001405.1", "GCF_000001405.40"},
+		{"no version", versions, "GCF_000001405", "GCF_000001405.40"},
+		{"missing", builtin, "GCF_999999999.1", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assembly, found := test.assemblies.Find(test.accession)
+			if found != (test.want != "") {
+				t.Fatalf("Find(%q) found = %v, want %v", test.accession, found, test.want != "")
+			}
+			if found && assembly.Accession != test.want {
+				t.Errorf("Find(%q) = %s, want %s", test.accession, assembly.Accession, test.want)
+			}
+		})
+	}
+	if assembly, _ := builtin.Find("GCF_000005845.2"); assembly.TaxID != 511145 || assembly.SpeciesTaxID != 562 {
+		t.Errorf("GCF_000005845.2 has taxid %d and species %d, want 511145 and 562", assembly.TaxID, assembly.SpeciesTaxID)
+	}
+}